@@ -0,0 +1,232 @@
+package gohelpers
+
+import (
+	"math/bits"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// Arithmetic on unsigned integers made of 64-bit limbs, least significant limb first,
+// shared by U128 and U256. The results are written into slices provided by the caller
+// (backed by arrays on the stack), to keep the allocations low under TinyGo.
+
+const maxLimbs = 4
+
+func isZeroLimbs(a []uint64) bool {
+	for _, x := range a {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func cmpLimbs(a []uint64, b []uint64) int {
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// r = a + b, returns the carry
+func addLimbs(r []uint64, a []uint64, b []uint64) uint64 {
+	var carry uint64
+	for i := range a {
+		r[i], carry = bits.Add64(a[i], b[i], carry)
+	}
+	return carry
+}
+
+// r = a - b, returns the borrow
+func subLimbs(r []uint64, a []uint64, b []uint64) uint64 {
+	var borrow uint64
+	for i := range a {
+		r[i], borrow = bits.Sub64(a[i], b[i], borrow)
+	}
+	return borrow
+}
+
+// r = a * b, returns whether the product overflows
+func mulLimbs(r []uint64, a []uint64, b []uint64) bool {
+	n := len(a)
+	var t [2 * maxLimbs]uint64
+	for i := 0; i < n; i++ {
+		var carry uint64
+		for j := 0; j < n; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j] = lo
+			carry = hi
+		}
+		t[i+n] = carry
+	}
+	copy(r, t[:n])
+	return !isZeroLimbs(t[n : 2*n])
+}
+
+// r = a * m, with one more limb in r than in a
+func mulSmallLimbs(r []uint64, a []uint64, m uint64) {
+	var carry uint64
+	for i := range a {
+		hi, lo := bits.Mul64(a[i], m)
+		var c uint64
+		r[i], c = bits.Add64(lo, carry, 0)
+		carry = hi + c
+	}
+	r[len(a)] = carry
+}
+
+// q = a / d, returns the remainder
+func divSmallLimbs(q []uint64, a []uint64, d uint64) uint64 {
+	var rem uint64
+	for i := len(a) - 1; i >= 0; i-- {
+		q[i], rem = bits.Div64(rem, a[i], d)
+	}
+	return rem
+}
+
+// q = a / b, rem = a % b; b must not be zero
+func divModLimbs(q []uint64, rem []uint64, a []uint64, b []uint64) {
+	n := len(a)
+	for i := range q {
+		q[i] = 0
+		rem[i] = 0
+	}
+	if isZeroLimbs(b[1:]) {
+		rem[0] = divSmallLimbs(q, a, b[0])
+		return
+	}
+	// Long division, bit by bit
+	for i := n*64 - 1; i >= 0; i-- {
+		top := rem[n-1] >> 63
+		for j := n - 1; j > 0; j-- {
+			rem[j] = rem[j]<<1 | rem[j-1]>>63
+		}
+		rem[0] = rem[0]<<1 | (a[i/64]>>uint(i%64))&1
+		if top != 0 || cmpLimbs(rem, b) >= 0 {
+			subLimbs(rem, rem, b)
+			q[i/64] |= 1 << uint(i%64)
+		}
+	}
+}
+
+// r = a * parts / accuracy, returns whether the result overflows
+func mulRatioLimbs(r []uint64, a []uint64, p srprimitives.PerThing, rounding srprimitives.Rounding) bool {
+	n := len(a)
+	var product, quotient [maxLimbs + 1]uint64
+	mulSmallLimbs(product[:n+1], a, p.Parts())
+	d := p.Accuracy()
+	rem := divSmallLimbs(quotient[:n+1], product[:n+1], d)
+	if (rounding == srprimitives.RoundUp && rem > 0) || (rounding == srprimitives.RoundNearest && rem >= d-rem) {
+		var one [maxLimbs + 1]uint64
+		one[0] = 1
+		addLimbs(quotient[:n+1], quotient[:n+1], one[:n+1])
+	}
+	copy(r, quotient[:n])
+	return quotient[n] != 0
+}
+
+func setMaxLimbs(r []uint64) {
+	for i := range r {
+		r[i] = ^uint64(0)
+	}
+}
+
+// Formats as a decimal number, taking 19 digits at a time
+func formatLimbs(a []uint64) string {
+	const chunk = 10000000000000000000 // 10^19
+	var buf [20 * maxLimbs]byte
+	var v [maxLimbs]uint64
+	n := len(a)
+	copy(v[:n], a)
+	pos := len(buf)
+	for {
+		rem := divSmallLimbs(v[:n], v[:n], chunk)
+		last := isZeroLimbs(v[:n])
+		for i := 0; i < 19 && (!last || rem > 0 || i == 0); i++ {
+			pos--
+			buf[pos] = byte('0' + rem%10)
+			rem /= 10
+		}
+		if last {
+			return string(buf[pos:])
+		}
+	}
+}
+
+func encodeFixedLimbs(pe codec.Encoder, a []uint64) {
+	for _, x := range a {
+		pe.EncodeUint64(x)
+	}
+}
+
+func decodeFixedLimbs(pd codec.Decoder, a []uint64) {
+	for i := range a {
+		a[i] = pd.DecodeUint64()
+	}
+}
+
+// The compact encoding: values below 2^64 are encoded as u64 would be, larger ones
+// in the "big integer" mode, i.e. the number of bytes followed by the little-endian bytes
+func encodeCompactLimbs(pe codec.Encoder, a []uint64) {
+	if isZeroLimbs(a[1:]) {
+		pe.EncodeUintCompact(a[0])
+		return
+	}
+	var buf [8 * maxLimbs]byte
+	size := 0
+	for i, x := range a {
+		for j := 0; j < 8; j++ {
+			buf[i*8+j] = byte(x >> uint(8*j))
+			if buf[i*8+j] != 0 {
+				size = i*8 + j + 1
+			}
+		}
+	}
+	pe.EncodeByte(byte((size-4)<<2 | 3))
+	pe.Write(buf[:size])
+}
+
+func decodeCompactLimbs(pd codec.Decoder, a []uint64) {
+	for i := range a {
+		a[i] = 0
+	}
+	b := pd.DecodeByte()
+	var buf [8 * maxLimbs]byte
+	var size int
+	switch b & 3 {
+	case 0:
+		a[0] = uint64(b >> 2)
+		return
+	case 1:
+		buf[0] = b
+		size = 2
+		pd.Read(buf[1:size])
+	case 2:
+		buf[0] = b
+		size = 4
+		pd.Read(buf[1:size])
+	default:
+		size = int(b>>2) + 4
+		if size > 8*len(a) {
+			panic("Compact integer is too large")
+		}
+		pd.Read(buf[:size])
+	}
+	for i := 0; i < size; i++ {
+		a[i/8] |= uint64(buf[i]) << uint(8*(i%8))
+	}
+	if b&3 != 3 {
+		a[0] >>= 2
+	}
+}
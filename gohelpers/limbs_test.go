@@ -0,0 +1,303 @@
+package gohelpers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// U128 and U256 are checked against math/big
+
+func bigOf(limbs []uint64) *big.Int {
+	r := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		r.Lsh(r, 64)
+		r.Or(r, new(big.Int).SetUint64(limbs[i]))
+	}
+	return r
+}
+
+func maxBig(bits uint) *big.Int {
+	return new(big.Int).Sub(bigPow2(bits), big.NewInt(1))
+}
+
+func bigPow2(n uint) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), n)
+}
+
+// The limbs of v, which must fit
+func limbsOf(v *big.Int, n int) []uint64 {
+	r := make([]uint64, n)
+	mask := new(big.Int).SetUint64(^uint64(0))
+	x := new(big.Int).Set(v)
+	for i := range r {
+		r[i] = new(big.Int).And(x, mask).Uint64()
+		x.Rsh(x, 64)
+	}
+	return r
+}
+
+func bigU128(v *big.Int) U128 {
+	var r U128
+	copy(r[:], limbsOf(v, 2))
+	return r
+}
+
+func bigU256(v *big.Int) U256 {
+	var r U256
+	copy(r[:], limbsOf(v, 4))
+	return r
+}
+
+func parseBig(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		panic(s)
+	}
+	return v
+}
+
+// Values around the limb boundaries, and some with all the limbs used
+func testValues(bits uint) []*big.Int {
+	max := new(big.Int).Sub(bigPow2(bits), big.NewInt(1))
+	values := []*big.Int{
+		big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(10),
+		big.NewInt(1 << 32), parseBig("10000000000000000000"),
+		parseBig("0xdeadbeefcafebabe1234567890abcdef"),
+		new(big.Int).Sub(max, big.NewInt(1)), max,
+	}
+	for n := uint(63); n < bits; n += 64 {
+		p := bigPow2(n + 1)
+		values = append(values, bigPow2(n), new(big.Int).Sub(p, big.NewInt(1)), p, new(big.Int).Add(p, big.NewInt(1)))
+	}
+	values = append(values, new(big.Int).Div(max, big.NewInt(3)), new(big.Int).Rsh(max, bits/2))
+	var fit []*big.Int
+	for _, v := range values {
+		if v.Cmp(max) <= 0 {
+			fit = append(fit, v)
+		}
+	}
+	return fit
+}
+
+// The result of an operation in math/big, and whether it fits
+type expected struct {
+	ok bool
+	v  *big.Int
+}
+
+func expectedOps(a *big.Int, b *big.Int, bits uint) map[string]expected {
+	max := new(big.Int).Sub(bigPow2(bits), big.NewInt(1))
+	fits := func(v *big.Int) expected { return expected{v.Sign() >= 0 && v.Cmp(max) <= 0, v} }
+	e := map[string]expected{
+		"add": fits(new(big.Int).Add(a, b)),
+		"sub": fits(new(big.Int).Sub(a, b)),
+		"mul": fits(new(big.Int).Mul(a, b)),
+	}
+	if b.Sign() != 0 {
+		q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+		e["div"], e["rem"] = expected{true, q}, expected{true, r}
+	} else {
+		e["div"], e["rem"] = expected{false, nil}, expected{false, nil}
+	}
+	return e
+}
+
+func TestU128Ops(t *testing.T) {
+	max := maxBig(128)
+	for _, a := range testValues(128) {
+		for _, b := range testValues(128) {
+			x, y := bigU128(a), bigU128(b)
+			results := map[string]func() (bool, U128){"add": func() (bool, U128) { return x.CheckedAdd(y) },
+				"sub": func() (bool, U128) { return x.CheckedSub(y) }, "mul": func() (bool, U128) { return x.CheckedMul(y) },
+				"div": func() (bool, U128) { return x.CheckedDiv(y) }, "rem": func() (bool, U128) { return x.CheckedRem(y) }}
+			for op, e := range expectedOps(a, b, 128) {
+				ok, r := results[op]()
+				if ok != e.ok || (ok && bigOf(r[:]).Cmp(e.v) != 0) {
+					t.Errorf("%v %s %v: got %v, %v", a, op, b, ok, bigOf(r[:]))
+				}
+			}
+			// The saturating operations saturate at the maximum, or at zero for sub
+			e := expectedOps(a, b, 128)
+			sat := func(e expected, bound *big.Int) *big.Int {
+				if e.ok {
+					return e.v
+				}
+				return bound
+			}
+			if r := x.SaturatingAdd(y); bigOf(r[:]).Cmp(sat(e["add"], max)) != 0 {
+				t.Errorf("%v saturating add %v: got %v", a, b, bigOf(r[:]))
+			}
+			if r := x.SaturatingSub(y); bigOf(r[:]).Cmp(sat(e["sub"], big.NewInt(0))) != 0 {
+				t.Errorf("%v saturating sub %v: got %v", a, b, bigOf(r[:]))
+			}
+			if r := x.SaturatingMul(y); bigOf(r[:]).Cmp(sat(e["mul"], max)) != 0 {
+				t.Errorf("%v saturating mul %v: got %v", a, b, bigOf(r[:]))
+			}
+			if r := x.SaturatingDiv(y); bigOf(r[:]).Cmp(sat(e["div"], max)) != 0 {
+				t.Errorf("%v saturating div %v: got %v", a, b, bigOf(r[:]))
+			}
+			if x.Cmp(y) != a.Cmp(b) || x.LessThan(y) != (a.Cmp(b) < 0) {
+				t.Errorf("%v cmp %v", a, b)
+			}
+		}
+	}
+}
+
+func TestU256Ops(t *testing.T) {
+	max := maxBig(256)
+	for _, a := range testValues(256) {
+		for _, b := range testValues(256) {
+			x, y := bigU256(a), bigU256(b)
+			results := map[string]func() (bool, U256){"add": func() (bool, U256) { return x.CheckedAdd(y) },
+				"sub": func() (bool, U256) { return x.CheckedSub(y) }, "mul": func() (bool, U256) { return x.CheckedMul(y) },
+				"div": func() (bool, U256) { return x.CheckedDiv(y) }, "rem": func() (bool, U256) { return x.CheckedRem(y) }}
+			for op, e := range expectedOps(a, b, 256) {
+				ok, r := results[op]()
+				if ok != e.ok || (ok && bigOf(r[:]).Cmp(e.v) != 0) {
+					t.Errorf("%v %s %v: got %v, %v", a, op, b, ok, bigOf(r[:]))
+				}
+			}
+			e := expectedOps(a, b, 256)
+			if r := x.SaturatingMul(y); e["mul"].ok && bigOf(r[:]).Cmp(e["mul"].v) != 0 || !e["mul"].ok && bigOf(r[:]).Cmp(max) != 0 {
+				t.Errorf("%v saturating mul %v: got %v", a, b, bigOf(r[:]))
+			}
+			if x.Cmp(y) != a.Cmp(b) {
+				t.Errorf("%v cmp %v", a, b)
+			}
+		}
+	}
+}
+
+func TestDivModPanicsOnZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("dividing by zero should panic")
+		}
+	}()
+	U128From64(1).DivMod(U128{})
+}
+
+func TestString(t *testing.T) {
+	for _, v := range testValues(128) {
+		if s := bigU128(v).String(); s != v.String() {
+			t.Errorf("U128: got %s, expected %s", s, v)
+		}
+	}
+	for _, v := range testValues(256) {
+		if s := bigU256(v).String(); s != v.String() {
+			t.Errorf("U256: got %s, expected %s", s, v)
+		}
+	}
+	// Chunks of 19 digits with leading zeros
+	v := parseBig("100000000000000000000000000000000000005")
+	if s := bigU128(v).String(); s != v.String() {
+		t.Errorf("got %s, expected %s", s, v)
+	}
+}
+
+func TestCompactEncoding(t *testing.T) {
+	ff := func(n int) string { return hex.EncodeToString(bytes.Repeat([]byte{0xff}, n)) }
+	cases := []struct {
+		value   *big.Int
+		encoded string
+	}{
+		{big.NewInt(0), "00"},
+		{big.NewInt(63), "fc"},
+		{big.NewInt(64), "0101"},
+		{big.NewInt(1 << 30), "0300000040"},
+		// The last value of the u64 range, then the first one of the bigger numbers
+		{new(big.Int).Sub(bigPow2(64), big.NewInt(1)), "13" + ff(8)},
+		{bigPow2(64), "17" + "000000000000000001"},
+		{new(big.Int).Add(bigPow2(64), big.NewInt(1)), "17" + "010000000000000001"},
+		{new(big.Int).Sub(bigPow2(128), big.NewInt(1)), "33" + ff(16)},
+	}
+	for _, c := range cases {
+		encoded := codec.ToBytesCustom(func(pe codec.Encoder) { bigU128(c.value).ParityEncodeCompact(pe) })
+		if hex.EncodeToString(encoded) != c.encoded {
+			t.Errorf("U128 %v: got %x, expected %s", c.value, encoded, c.encoded)
+		}
+		var decoded U128
+		decoded.ParityDecodeCompact(codec.Decoder{bytes.NewBuffer(encoded)})
+		if bigOf(decoded[:]).Cmp(c.value) != 0 {
+			t.Errorf("U128 %v: decoded %v", c.value, bigOf(decoded[:]))
+		}
+		// The same in U256
+		encoded = codec.ToBytesCustom(func(pe codec.Encoder) { bigU256(c.value).ParityEncodeCompact(pe) })
+		if hex.EncodeToString(encoded) != c.encoded {
+			t.Errorf("U256 %v: got %x, expected %s", c.value, encoded, c.encoded)
+		}
+	}
+
+	maxEncoded := codec.ToBytesCustom(func(pe codec.Encoder) { MaxU256().ParityEncodeCompact(pe) })
+	if hex.EncodeToString(maxEncoded) != "73"+ff(32) {
+		t.Errorf("max U256: got %x", maxEncoded)
+	}
+	var max U256
+	max.ParityDecodeCompact(codec.Decoder{bytes.NewBuffer(maxEncoded)})
+	if max != MaxU256() {
+		t.Errorf("max U256: decoded %v", max)
+	}
+	for _, v := range testValues(256) {
+		encoded := codec.ToBytesCustom(func(pe codec.Encoder) { bigU256(v).ParityEncodeCompact(pe) })
+		var decoded U256
+		decoded.ParityDecodeCompact(codec.Decoder{bytes.NewBuffer(encoded)})
+		if decoded != bigU256(v) {
+			t.Errorf("U256 %v: decoded %v", v, decoded)
+		}
+	}
+}
+
+func TestCompactTooLargeForU128(t *testing.T) {
+	// 2^128, 17 bytes
+	encoded := codec.ToBytesCustom(func(pe codec.Encoder) { bigU256(bigPow2(128)).ParityEncodeCompact(pe) })
+	defer func() {
+		if recover() == nil {
+			t.Error("decoding 2^128 into a U128 should panic")
+		}
+	}()
+	var v U128
+	v.ParityDecodeCompact(codec.Decoder{bytes.NewBuffer(encoded)})
+}
+
+func TestMulRatio(t *testing.T) {
+	ratios := []srprimitives.PerThing{
+		srprimitives.Perbill(0), srprimitives.Perbill(1), srprimitives.Perbill(500000000),
+		srprimitives.Perbill(999999999), srprimitives.Perbill(1000000000),
+		srprimitives.Permill(333333), srprimitives.Perquintill(123456789012345678),
+		srprimitives.FixedU64(2500000000),
+	}
+	roundings := []srprimitives.Rounding{srprimitives.RoundDown, srprimitives.RoundNearest, srprimitives.RoundUp}
+	expect := func(v *big.Int, p srprimitives.PerThing, r srprimitives.Rounding, max *big.Int) *big.Int {
+		d := new(big.Int).SetUint64(p.Accuracy())
+		q, rem := new(big.Int).QuoRem(new(big.Int).Mul(v, new(big.Int).SetUint64(p.Parts())), d, new(big.Int))
+		// Half up for RoundNearest
+		if (r == srprimitives.RoundUp && rem.Sign() > 0) || (r == srprimitives.RoundNearest && new(big.Int).Lsh(rem, 1).Cmp(d) >= 0) {
+			q.Add(q, big.NewInt(1))
+		}
+		if q.Cmp(max) > 0 {
+			return max
+		}
+		return q
+	}
+	for _, p := range ratios {
+		for _, r := range roundings {
+			for _, v := range testValues(128) {
+				got := bigU128(v).MulRatio(p, r)
+				if e := expect(v, p, r, maxBig(128)); bigOf(got[:]).Cmp(e) != 0 {
+					t.Errorf("U128 %v * %d/%d (%d): got %v, expected %v", v, p.Parts(), p.Accuracy(), r, bigOf(got[:]), e)
+				}
+			}
+			for _, v := range testValues(256) {
+				got := bigU256(v).MulRatio(p, r)
+				if e := expect(v, p, r, maxBig(256)); bigOf(got[:]).Cmp(e) != 0 {
+					t.Errorf("U256 %v * %d/%d (%d): got %v, expected %v", v, p.Parts(), p.Accuracy(), r, bigOf(got[:]), e)
+				}
+			}
+		}
+	}
+}
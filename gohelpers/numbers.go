@@ -0,0 +1,204 @@
+package gohelpers
+
+import (
+	"math"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// The implementation of srprimitives.Number for u32, u64 and u128: a single type which
+// knows its width. The operations are done on U128 values, so that the numbers of
+// different widths can be mixed.
+
+/// An unsigned integer of 32, 64 or 128 bits; *Uint implements srprimitives.Number.
+/// The width determines the encoding, and the maximum at which the operations saturate
+/// or report overflows. Create the numbers with NewUint32, NewUint64 and NewUint128.
+type Uint struct {
+	value U128
+	// In bytes: 4, 8 or 16
+	width byte
+}
+
+/// A u32 number
+func NewUint32(v uint32) *Uint {
+	return &Uint{U128From64(uint64(v)), 4}
+}
+
+/// A u64 number
+func NewUint64(v uint64) *Uint {
+	return &Uint{U128From64(v), 8}
+}
+
+/// A u128 number
+func NewUint128(v U128) *Uint {
+	return &Uint{v, 16}
+}
+
+/// The value, for the u128 numbers which can not be converted with AsUint64
+func (v *Uint) Value() U128 {
+	return v.value
+}
+
+func (v *Uint) maxValue() U128 {
+	switch v.width {
+	case 4:
+		return U128From64(math.MaxUint32)
+	case 8:
+		return U128From64(math.MaxUint64)
+	case 16:
+		return MaxU128()
+	}
+	panic("Uint must be created with NewUint32, NewUint64 or NewUint128")
+}
+
+// A number of the same width, the value must fit
+func (v *Uint) wrap(u U128) srprimitives.Number {
+	return &Uint{u, v.width}
+}
+
+func (v *Uint) wrapChecked(ok bool, u U128) (bool, srprimitives.Number) {
+	if !ok || v.maxValue().LessThan(u) {
+		return false, nil
+	}
+	return true, v.wrap(u)
+}
+
+func (v *Uint) wrapSaturating(u U128) srprimitives.Number {
+	max := v.maxValue()
+	if max.LessThan(u) {
+		return v.wrap(max)
+	}
+	return v.wrap(u)
+}
+
+func u128Of(n srprimitives.Number) U128 {
+	lo, hi := n.AsUint128()
+	return U128{lo, hi}
+}
+
+func (v *Uint) ParityEncode(pe codec.Encoder) {
+	switch v.width {
+	case 4:
+		pe.EncodeUint32(uint32(v.value[0]))
+	case 8:
+		pe.EncodeUint64(v.value[0])
+	default:
+		v.value.ParityEncode(pe)
+	}
+}
+
+func (v *Uint) ParityDecode(pd codec.Decoder) {
+	switch v.width {
+	case 4:
+		v.value = U128From64(uint64(pd.DecodeUint32()))
+	case 8:
+		v.value = U128From64(pd.DecodeUint64())
+	default:
+		v.value.ParityDecode(pd)
+	}
+}
+
+func (v *Uint) ParityEncodeCompact(pe codec.Encoder) {
+	v.value.ParityEncodeCompact(pe)
+}
+
+/// Panics if the value does not fit the width, as decoding Compact<u32> fails in Rust.
+func (v *Uint) ParityDecodeCompact(pd codec.Decoder) {
+	var u U128
+	u.ParityDecodeCompact(pd)
+	if v.maxValue().LessThan(u) {
+		panic("Compact integer is too large")
+	}
+	v.value = u
+}
+
+func (v *Uint) AsUint64() uint64 {
+	if v.value[1] != 0 {
+		return math.MaxUint64
+	}
+	return v.value[0]
+}
+
+func (v *Uint) AsUint128() (uint64, uint64) {
+	return v.value[0], v.value[1]
+}
+
+func (v *Uint) FromUint64(i uint64) srprimitives.Number {
+	return v.wrapSaturating(U128From64(i))
+}
+
+func (v *Uint) FromUint128(lo uint64, hi uint64) srprimitives.Number {
+	return v.wrapSaturating(U128{lo, hi})
+}
+
+func (v *Uint) Zero() srprimitives.Number {
+	return v.wrap(U128{})
+}
+
+func (v *Uint) One() srprimitives.Number {
+	return v.wrap(U128From64(1))
+}
+
+func (v *Uint) IsZero() bool {
+	return v.value.IsZero()
+}
+
+func (v *Uint) NonZero() bool {
+	return !v.IsZero()
+}
+
+func (v *Uint) Cmp(o srprimitives.Number) int {
+	return v.value.Cmp(u128Of(o))
+}
+
+func (v *Uint) Equal(o srprimitives.Number) bool {
+	return v.Cmp(o) == 0
+}
+
+func (v *Uint) LessThan(o srprimitives.Number) bool {
+	return v.Cmp(o) < 0
+}
+
+func (v *Uint) GreaterThan(o srprimitives.Number) bool {
+	return v.Cmp(o) > 0
+}
+
+func (v *Uint) CheckedAdd(o srprimitives.Number) (bool, srprimitives.Number) {
+	return v.wrapChecked(v.value.CheckedAdd(u128Of(o)))
+}
+
+func (v *Uint) CheckedSub(o srprimitives.Number) (bool, srprimitives.Number) {
+	return v.wrapChecked(v.value.CheckedSub(u128Of(o)))
+}
+
+func (v *Uint) CheckedMul(o srprimitives.Number) (bool, srprimitives.Number) {
+	return v.wrapChecked(v.value.CheckedMul(u128Of(o)))
+}
+
+func (v *Uint) SaturatingAdd(o srprimitives.Number) srprimitives.Number {
+	return v.wrapSaturating(v.value.SaturatingAdd(u128Of(o)))
+}
+
+func (v *Uint) SaturatingSub(o srprimitives.Number) srprimitives.Number {
+	return v.wrap(v.value.SaturatingSub(u128Of(o)))
+}
+
+func (v *Uint) SaturatingMul(o srprimitives.Number) srprimitives.Number {
+	return v.wrapSaturating(v.value.SaturatingMul(u128Of(o)))
+}
+
+func (v *Uint) Plus(i int) srprimitives.Number {
+	if i < 0 {
+		return v.wrap(v.value.SaturatingSub(U128From64(uint64(-i))))
+	}
+	return v.wrapSaturating(v.value.SaturatingAdd(U128From64(uint64(i))))
+}
+
+func (v *Uint) PlusOne() srprimitives.Number {
+	return v.Plus(1)
+}
+
+func (v *Uint) MinusOne() srprimitives.Number {
+	return v.Plus(-1)
+}
@@ -0,0 +1,155 @@
+package gohelpers
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+func u64Of(t *testing.T, n srprimitives.Number, width byte) uint64 {
+	if n.(*Uint).width != width {
+		t.Fatalf("the result has width %d, expected %d", n.(*Uint).width, width)
+	}
+	return n.AsUint64()
+}
+
+func TestCheckedOps(t *testing.T) {
+	max32 := NewUint32(math.MaxUint32)
+	if ok, _ := max32.CheckedAdd(NewUint32(1)); ok {
+		t.Error("u32 max + 1 should overflow")
+	}
+	if ok, r := max32.CheckedSub(NewUint32(1)); !ok || u64Of(t, r, 4) != math.MaxUint32-1 {
+		t.Error("u32 max - 1")
+	}
+	if ok, _ := NewUint32(0).CheckedSub(NewUint32(1)); ok {
+		t.Error("0 - 1 should underflow")
+	}
+	if ok, _ := NewUint32(1 << 16).CheckedMul(NewUint32(1 << 16)); ok {
+		t.Error("2^16 * 2^16 should overflow u32")
+	}
+	if ok, r := NewUint64(1 << 16).CheckedMul(NewUint32(1 << 16)); !ok || u64Of(t, r, 8) != 1<<32 {
+		t.Error("2^16 * 2^16 should fit u64")
+	}
+	if ok, _ := NewUint64(math.MaxUint64).CheckedAdd(NewUint64(1)); ok {
+		t.Error("u64 max + 1 should overflow")
+	}
+	if ok, r := NewUint128(U128From64(math.MaxUint64)).CheckedAdd(NewUint64(1)); !ok || r.(*Uint).Value() != (U128{0, 1}) {
+		t.Error("u64 max + 1 should fit u128")
+	}
+	if ok, _ := NewUint128(MaxU128()).CheckedMul(NewUint32(2)); ok {
+		t.Error("u128 max * 2 should overflow")
+	}
+}
+
+func TestSaturatingOps(t *testing.T) {
+	if r := NewUint32(math.MaxUint32).SaturatingAdd(NewUint32(5)); u64Of(t, r, 4) != math.MaxUint32 {
+		t.Error("u32 saturating add")
+	}
+	if r := NewUint32(3).SaturatingSub(NewUint64(5)); u64Of(t, r, 4) != 0 {
+		t.Error("u32 saturating sub")
+	}
+	if r := NewUint64(1 << 40).SaturatingMul(NewUint64(1 << 40)); u64Of(t, r, 8) != math.MaxUint64 {
+		t.Error("u64 saturating mul")
+	}
+	if r := NewUint128(MaxU128()).SaturatingAdd(NewUint128(MaxU128())); r.(*Uint).Value() != MaxU128() {
+		t.Error("u128 saturating add")
+	}
+	// A u32 receiver saturates at the u32 maximum, whatever the width of the argument
+	if r := NewUint32(1).SaturatingAdd(NewUint64(math.MaxUint64)); u64Of(t, r, 4) != math.MaxUint32 {
+		t.Error("u32 + u64 max")
+	}
+	if r := NewUint32(7).FromUint64(math.MaxUint64); u64Of(t, r, 4) != math.MaxUint32 {
+		t.Error("u32 FromUint64")
+	}
+	if r := NewUint32(math.MaxUint32).Plus(10); u64Of(t, r, 4) != math.MaxUint32 {
+		t.Error("u32 Plus")
+	}
+	if r := NewUint64(5).Plus(-10); u64Of(t, r, 8) != 0 {
+		t.Error("u64 Plus of a negative")
+	}
+}
+
+func TestMinusOneAtZero(t *testing.T) {
+	for _, n := range []*Uint{NewUint32(0), NewUint64(0), NewUint128(U128{})} {
+		r := n.MinusOne()
+		if !r.IsZero() || r.(*Uint).width != n.width {
+			t.Errorf("MinusOne of a zero of width %d", n.width)
+		}
+	}
+	if r := NewUint64(1).MinusOne(); !r.IsZero() {
+		t.Error("1 - 1")
+	}
+}
+
+func TestMixedCmp(t *testing.T) {
+	big := NewUint128(U128{0, 1})
+	cases := []struct {
+		a, b srprimitives.Number
+		cmp  int
+	}{
+		{NewUint32(5), NewUint64(5), 0},
+		{NewUint32(5), NewUint128(U128From64(5)), 0},
+		{NewUint32(math.MaxUint32), NewUint64(math.MaxUint32 + 1), -1},
+		{NewUint64(math.MaxUint64), big, -1},
+		{big, NewUint32(math.MaxUint32), 1},
+		{NewUint64(0), NewUint32(0), 0},
+	}
+	for i, c := range cases {
+		if c.a.Cmp(c.b) != c.cmp || c.b.Cmp(c.a) != -c.cmp {
+			t.Errorf("case %d: Cmp", i)
+		}
+		if c.a.Equal(c.b) != (c.cmp == 0) || c.a.LessThan(c.b) != (c.cmp < 0) || c.a.GreaterThan(c.b) != (c.cmp > 0) {
+			t.Errorf("case %d: Equal, LessThan, GreaterThan", i)
+		}
+	}
+	if NewUint128(U128{0, 1}).AsUint64() != math.MaxUint64 {
+		t.Error("AsUint64 should saturate")
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	cases := []struct {
+		n       *Uint
+		encoded []byte
+	}{
+		{NewUint32(0x01020304), []byte{4, 3, 2, 1}},
+		{NewUint64(1), []byte{1, 0, 0, 0, 0, 0, 0, 0}},
+		{NewUint128(U128{1, 2}), []byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, c := range cases {
+		if !bytes.Equal(codec.ToBytes(c.n), c.encoded) {
+			t.Errorf("width %d: got %x, expected %x", c.n.width, codec.ToBytes(c.n), c.encoded)
+		}
+		decoded := c.n.Zero().(*Uint)
+		decoded.ParityDecode(codec.Decoder{bytes.NewBuffer(c.encoded)})
+		if *decoded != *c.n {
+			t.Errorf("width %d: decoding", c.n.width)
+		}
+	}
+}
+
+func decodeCompact(n *Uint, encoded []byte) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	n.ParityDecodeCompact(codec.Decoder{bytes.NewBuffer(encoded)})
+	return false
+}
+
+func TestCompactDecodingChecksTheWidth(t *testing.T) {
+	// 2^32, in the big integer mode
+	encoded := codec.ToBytesCustom(func(pe codec.Encoder) { NewUint64(1 << 32).ParityEncodeCompact(pe) })
+	if !decodeCompact(NewUint32(0), encoded) {
+		t.Error("a u32 should not decode 2^32")
+	}
+	n := NewUint64(0)
+	if decodeCompact(n, encoded) || n.AsUint64() != 1<<32 {
+		t.Error("a u64 should decode 2^32")
+	}
+	maxU32 := codec.ToBytesCustom(func(pe codec.Encoder) { NewUint32(math.MaxUint32).ParityEncodeCompact(pe) })
+	n = NewUint32(0)
+	if decodeCompact(n, maxU32) || n.AsUint64() != math.MaxUint32 {
+		t.Error("a u32 should decode its maximum")
+	}
+}
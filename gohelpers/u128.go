@@ -0,0 +1,154 @@
+package gohelpers
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+/// A 128-bit unsigned integer, as 2 limbs of 64 bits, least significant first.
+/// The operations do not modify their receivers.
+type U128 [2]uint64
+
+func U128From64(v uint64) U128 {
+	return U128{v, 0}
+}
+
+func MaxU128() U128 {
+	var r U128
+	setMaxLimbs(r[:])
+	return r
+}
+
+func (a U128) IsZero() bool {
+	return isZeroLimbs(a[:])
+}
+
+/// Returns -1, 0 or 1 if a is less than, equal to or greater than b
+func (a U128) Cmp(b U128) int {
+	return cmpLimbs(a[:], b[:])
+}
+
+func (a U128) LessThan(b U128) bool {
+	return a.Cmp(b) < 0
+}
+
+/// Whether the value fits into 64 bits, and the value
+func (a U128) AsUint64() (bool, uint64) {
+	return isZeroLimbs(a[1:]), a[0]
+}
+
+func (a U128) CheckedAdd(b U128) (bool, U128) {
+	var r U128
+	if addLimbs(r[:], a[:], b[:]) != 0 {
+		return false, U128{}
+	}
+	return true, r
+}
+
+func (a U128) CheckedSub(b U128) (bool, U128) {
+	var r U128
+	if subLimbs(r[:], a[:], b[:]) != 0 {
+		return false, U128{}
+	}
+	return true, r
+}
+
+func (a U128) CheckedMul(b U128) (bool, U128) {
+	var r U128
+	if mulLimbs(r[:], a[:], b[:]) {
+		return false, U128{}
+	}
+	return true, r
+}
+
+/// Fails when dividing by zero
+func (a U128) CheckedDiv(b U128) (bool, U128) {
+	if b.IsZero() {
+		return false, U128{}
+	}
+	q, _ := a.DivMod(b)
+	return true, q
+}
+
+/// Fails when dividing by zero
+func (a U128) CheckedRem(b U128) (bool, U128) {
+	if b.IsZero() {
+		return false, U128{}
+	}
+	_, rem := a.DivMod(b)
+	return true, rem
+}
+
+/// The quotient and the remainder, panics when dividing by zero
+func (a U128) DivMod(b U128) (U128, U128) {
+	if b.IsZero() {
+		panic("division by zero")
+	}
+	var q, rem U128
+	divModLimbs(q[:], rem[:], a[:], b[:])
+	return q, rem
+}
+
+func (a U128) SaturatingAdd(b U128) U128 {
+	ok, r := a.CheckedAdd(b)
+	if !ok {
+		return MaxU128()
+	}
+	return r
+}
+
+func (a U128) SaturatingSub(b U128) U128 {
+	ok, r := a.CheckedSub(b)
+	if !ok {
+		return U128{}
+	}
+	return r
+}
+
+func (a U128) SaturatingMul(b U128) U128 {
+	ok, r := a.CheckedMul(b)
+	if !ok {
+		return MaxU128()
+	}
+	return r
+}
+
+/// Division can not overflow, so this only saturates when dividing by zero
+func (a U128) SaturatingDiv(b U128) U128 {
+	ok, r := a.CheckedDiv(b)
+	if !ok {
+		return MaxU128()
+	}
+	return r
+}
+
+/// Multiplies by a ratio, e.g. srprimitives.Perbill, saturating for the ratios above one
+func (a U128) MulRatio(p srprimitives.PerThing, r srprimitives.Rounding) U128 {
+	var res U128
+	if mulRatioLimbs(res[:], a[:], p, r) {
+		return MaxU128()
+	}
+	return res
+}
+
+/// The decimal representation
+func (a U128) String() string {
+	return formatLimbs(a[:])
+}
+
+/// Encodes as 16 little-endian bytes
+func (a U128) ParityEncode(pe codec.Encoder) {
+	encodeFixedLimbs(pe, a[:])
+}
+
+func (a *U128) ParityDecode(pd codec.Decoder) {
+	decodeFixedLimbs(pd, a[:])
+}
+
+func (a U128) ParityEncodeCompact(pe codec.Encoder) {
+	encodeCompactLimbs(pe, a[:])
+}
+
+func (a *U128) ParityDecodeCompact(pd codec.Decoder) {
+	decodeCompactLimbs(pd, a[:])
+}
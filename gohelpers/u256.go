@@ -0,0 +1,163 @@
+package gohelpers
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+/// A 256-bit unsigned integer, as 4 limbs of 64 bits, least significant first.
+/// The operations do not modify their receivers.
+type U256 [4]uint64
+
+func U256From64(v uint64) U256 {
+	return U256{v, 0, 0, 0}
+}
+
+func U256FromU128(v U128) U256 {
+	return U256{v[0], v[1], 0, 0}
+}
+
+/// Whether the value fits into 128 bits, and the value
+func (a U256) AsU128() (bool, U128) {
+	return a[2] == 0 && a[3] == 0, U128{a[0], a[1]}
+}
+
+func MaxU256() U256 {
+	var r U256
+	setMaxLimbs(r[:])
+	return r
+}
+
+func (a U256) IsZero() bool {
+	return isZeroLimbs(a[:])
+}
+
+/// Returns -1, 0 or 1 if a is less than, equal to or greater than b
+func (a U256) Cmp(b U256) int {
+	return cmpLimbs(a[:], b[:])
+}
+
+func (a U256) LessThan(b U256) bool {
+	return a.Cmp(b) < 0
+}
+
+/// Whether the value fits into 64 bits, and the value
+func (a U256) AsUint64() (bool, uint64) {
+	return isZeroLimbs(a[1:]), a[0]
+}
+
+func (a U256) CheckedAdd(b U256) (bool, U256) {
+	var r U256
+	if addLimbs(r[:], a[:], b[:]) != 0 {
+		return false, U256{}
+	}
+	return true, r
+}
+
+func (a U256) CheckedSub(b U256) (bool, U256) {
+	var r U256
+	if subLimbs(r[:], a[:], b[:]) != 0 {
+		return false, U256{}
+	}
+	return true, r
+}
+
+func (a U256) CheckedMul(b U256) (bool, U256) {
+	var r U256
+	if mulLimbs(r[:], a[:], b[:]) {
+		return false, U256{}
+	}
+	return true, r
+}
+
+/// Fails when dividing by zero
+func (a U256) CheckedDiv(b U256) (bool, U256) {
+	if b.IsZero() {
+		return false, U256{}
+	}
+	q, _ := a.DivMod(b)
+	return true, q
+}
+
+/// Fails when dividing by zero
+func (a U256) CheckedRem(b U256) (bool, U256) {
+	if b.IsZero() {
+		return false, U256{}
+	}
+	_, rem := a.DivMod(b)
+	return true, rem
+}
+
+/// The quotient and the remainder, panics when dividing by zero
+func (a U256) DivMod(b U256) (U256, U256) {
+	if b.IsZero() {
+		panic("division by zero")
+	}
+	var q, rem U256
+	divModLimbs(q[:], rem[:], a[:], b[:])
+	return q, rem
+}
+
+func (a U256) SaturatingAdd(b U256) U256 {
+	ok, r := a.CheckedAdd(b)
+	if !ok {
+		return MaxU256()
+	}
+	return r
+}
+
+func (a U256) SaturatingSub(b U256) U256 {
+	ok, r := a.CheckedSub(b)
+	if !ok {
+		return U256{}
+	}
+	return r
+}
+
+func (a U256) SaturatingMul(b U256) U256 {
+	ok, r := a.CheckedMul(b)
+	if !ok {
+		return MaxU256()
+	}
+	return r
+}
+
+/// Division can not overflow, so this only saturates when dividing by zero
+func (a U256) SaturatingDiv(b U256) U256 {
+	ok, r := a.CheckedDiv(b)
+	if !ok {
+		return MaxU256()
+	}
+	return r
+}
+
+/// Multiplies by a ratio, e.g. srprimitives.Perbill, saturating for the ratios above one
+func (a U256) MulRatio(p srprimitives.PerThing, r srprimitives.Rounding) U256 {
+	var res U256
+	if mulRatioLimbs(res[:], a[:], p, r) {
+		return MaxU256()
+	}
+	return res
+}
+
+/// The decimal representation
+func (a U256) String() string {
+	return formatLimbs(a[:])
+}
+
+/// Encodes as 32 little-endian bytes
+func (a U256) ParityEncode(pe codec.Encoder) {
+	encodeFixedLimbs(pe, a[:])
+}
+
+func (a *U256) ParityDecode(pd codec.Decoder) {
+	decodeFixedLimbs(pd, a[:])
+}
+
+func (a U256) ParityEncodeCompact(pe codec.Encoder) {
+	encodeCompactLimbs(pe, a[:])
+}
+
+func (a *U256) ParityDecodeCompact(pd codec.Decoder) {
+	decodeCompactLimbs(pd, a[:])
+}
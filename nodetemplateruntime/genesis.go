@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	balancesmodule "github.com/Joystream/tinygo-wasm-substrate/srml/balances"
+	consensusmodule "github.com/Joystream/tinygo-wasm-substrate/srml/consensus"
+	indicesmodule "github.com/Joystream/tinygo-wasm-substrate/srml/indices"
+	sudomodule "github.com/Joystream/tinygo-wasm-substrate/srml/sudo"
+	timestampmodule "github.com/Joystream/tinygo-wasm-substrate/srml/timestamp"
+)
+
+// Initial state of the chain, as GenesisConfig generated by construct_runtime! in Rust.
+// The system and aura modules have no configuration.
+type GenesisConfig struct {
+	Consensus consensusmodule.GenesisConfig
+	Timestamp timestampmodule.GenesisConfig
+	Indices   indicesmodule.GenesisConfig
+	Balances  balancesmodule.GenesisConfig
+	Sudo      sudomodule.GenesisConfig
+}
+
+/// Writes the initial state of all the modules into the storage.
+func (c *GenesisConfig) BuildStorage() {
+	system.BuildStorage()
+	consensus.BuildStorage(c.Consensus)
+	timestamp.BuildStorage(c.Timestamp)
+	indices.BuildStorage(c.Indices)
+	balances.BuildStorage(c.Balances)
+	sudo.BuildStorage(c.Sudo)
+	migrations.BuildStorage()
+}
+
+/// The genesis of the local testnet chain spec of the node template.
+func TestnetGenesis(code []byte, initialAuthorities []SessionKey, endowedAccounts []AccountId, rootKey AccountId) GenesisConfig {
+	authorities := make(consensusmodule.Authorities, len(initialAuthorities))
+	for i := range initialAuthorities {
+		authorities[i] = &initialAuthorities[i]
+	}
+	ids := make(indicesmodule.AccountIds, len(endowedAccounts))
+	endowed := make([]balancesmodule.AccountBalance, len(endowedAccounts))
+	for i := range endowedAccounts {
+		ids[i] = &endowedAccounts[i]
+		endowed[i] = balancesmodule.AccountBalance{&endowedAccounts[i], Balance(gohelpers.U128From64(1 << 60))}
+	}
+
+	return GenesisConfig{
+		Consensus: consensusmodule.GenesisConfig{
+			Authorities: authorities,
+			Code:        code,
+		},
+		// 5*2=10 second block time.
+		Timestamp: timestampmodule.GenesisConfig{MinimumPeriod: 5},
+		Indices:   indicesmodule.GenesisConfig{Ids: ids},
+		Balances: balancesmodule.GenesisConfig{
+			Balances:           endowed,
+			ExistentialDeposit: Balance(gohelpers.U128From64(500)),
+			TransferFee:        Balance(gohelpers.U128From64(0)),
+			CreationFee:        Balance(gohelpers.U128From64(0)),
+			TransactionBaseFee: Balance(gohelpers.U128From64(1)),
+			TransactionByteFee: Balance(gohelpers.U128From64(0)),
+		},
+		Sudo: sudomodule.GenesisConfig{Key: srprimitives.AccountId(&rootKey)},
+	}
+}
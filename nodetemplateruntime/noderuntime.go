@@ -1,54 +1,25 @@
 package main
 
 import (
+	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/inherents"
-	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srio"
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/srversion"
+	auramodule "github.com/Joystream/tinygo-wasm-substrate/srml/aura"
+	balancesmodule "github.com/Joystream/tinygo-wasm-substrate/srml/balances"
+	consensusmodule "github.com/Joystream/tinygo-wasm-substrate/srml/consensus"
 	executivemodule "github.com/Joystream/tinygo-wasm-substrate/srml/executive"
-	metadatamodule "github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
-	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	indicesmodule "github.com/Joystream/tinygo-wasm-substrate/srml/indices"
+	sudomodule "github.com/Joystream/tinygo-wasm-substrate/srml/sudo"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/migration"
 	runtimemodule "github.com/Joystream/tinygo-wasm-substrate/srml/support/runtime"
+	systemmodule "github.com/Joystream/tinygo-wasm-substrate/srml/system"
+	timestampmodule "github.com/Joystream/tinygo-wasm-substrate/srml/timestamp"
+	. "github.com/Joystream/tinygo-wasm-substrate/wasmhelpers"
 	codec "github.com/kyegupov/parity-codec-go/noreflect"
 )
 
-/// Alias to Ed25519 pubkey that identifies an account on the chain.
-type AccountId primitives.H256
-
-/// A hash of some data used by the chain.
-type Hash primitives.H256
-
-/// Index of a block number in the chain.
-type BlockNumber uint64
-
-/// Index of an account's extrinsic in the chain.
-type Nonce uint64
-
-/// Opaque types. These are used by the CLI to instantiate machinery that don't need to know
-/// the specifics of the runtime. They can then be made to be agnostic over specific formats
-/// of data like extrinsics, allowing for them to continue syncing the network through upgrades
-/// to even the core datastructures.
-
-// type UncheckedExtrinsic []byte
-
-// func (*UncheckedExtrinsic) IsSigned() (bool, bool) {
-// 	return false, false
-// }
-
-func authorityIdFactory() srprimitives.AuthorityId { return &primitives.H256{} }
-
-func decodeDigestItem(pd codec.Decoder) srprimitives.DigestItem {
-	return srprimitives.DecodeDigestItem(pd, authorityIdFactory)
-}
-
-// func DecodeExtrinsic(pd codec.Decoder) UncheckedExtrinsic {
-// 	return UncheckedExtrinsic(pd.DecodeByteSlice())
-// }
-
-type SessionKey primitives.Ed25519AuthorityId
-
-// End opaque types
-
 /// This runtime version.
 var VERSION srversion.RuntimeVersion = srversion.RuntimeVersion{
 	SpecName:         "template-node",
@@ -56,205 +27,226 @@ var VERSION srversion.RuntimeVersion = srversion.RuntimeVersion{
 	AuthoringVersion: 3,
 	SpecVersion:      3,
 	ImplVersion:      0,
-	ApiVersions:      []srversion.ApiVersion{},
+	ApiVersions: srversion.ImplementedApis(
+		srversion.CoreApi,
+		srversion.MetadataApi,
+		srversion.BlockBuilderApi,
+		srversion.TaggedTransactionQueueApi,
+		srversion.OffchainWorkerApi,
+		srversion.AuraApi,
+	),
 }
 
-// impl system.Trait for Runtime {
-// 	/// The identifier used to distinguish between accounts.
-// 	type AccountId = AccountId;
-// 	/// The lookup mechanism to get account ID from whatever is passed in dispatchers.
-// 	type Lookup = Indices;
-// 	/// The index type for storing how many extrinsics an account has signed.
-// 	type Index = Nonce;
-// 	/// The index type for blocks.
-// 	type BlockNumber = BlockNumber;
-// 	/// The type for hashing blocks and tries.
-// 	type Hash = Hash;
-// 	/// The hashing algorithm used.
-// 	type Hashing = BlakeTwo256;
-// 	/// The header digest type.
-// 	type Digest = generic.Digest<Log>;
-// 	/// The header type.
-// 	type Header = generic.Header<BlockNumber, BlakeTwo256, Log>;
-// 	/// The ubiquitous event type.
-// 	type Event = Event;
-// 	/// The ubiquitous log type.
-// 	type Log = Log;
-// 	/// The ubiquitous origin type.
-// 	type Origin = Origin;
-// }
-
-// impl aura.Trait for Runtime {
-// 	type HandleReport = ();
-// }
-
-// impl consensus.Trait for Runtime {
-// 	/// The position in the block's extrinsics that the note-offline inherent must be placed.
-// 	const NOTE_OFFLINE_POSITION: u32 = 1;
-// 	/// The identifier we use to refer to authorities.
-// 	type SessionKey = Ed25519AuthorityId;
-// 	// The aura module handles offline-reports internally
-// 	// rather than using an explicit report system.
-// 	type InherentOfflineReport = ();
-// 	/// The ubiquitous log type.
-// 	type Log = Log;
-// }
-
-// impl indices.Trait for Runtime {
-// 	/// The type for recording indexing into the account enumeration. If this ever overflows, there
-// 	/// will be problems!
-// 	type AccountIndex = u32;
-// 	/// Use the standard means of resolving an index hint from an id.
-// 	type ResolveHint = indices.SimpleResolveHint<Self.AccountId, Self.AccountIndex>;
-// 	/// Determine whether an account is dead.
-// 	type IsDeadAccount = Balances;
-// 	/// The uniquitous event type.
-// 	type Event = Event;
-// }
-
-// impl timestamp.Trait for Runtime {
-// 	/// The position in the block's extrinsics that the timestamp-set inherent must be placed.
-// 	const TIMESTAMP_SET_POSITION: u32 = 0;
-// 	/// A timestamp: seconds since the unix epoch.
-// 	type Moment = uint64;
-// 	type OnTimestampSet = Aura;
-// }
-
-// impl balances.Trait for Runtime {
-// 	/// The type for recording an account's balance.
-// 	type Balance = u128;
-// 	/// What to do if an account's free balance gets zeroed.
-// 	type OnFreeBalanceZero = ();
-// 	/// What to do if a new account is created.
-// 	type OnNewAccount = Indices;
-// 	/// Restrict whether an account can transfer funds. We don't place any further restrictions.
-// 	type EnsureAccountLiquid = ();
-// 	/// The uniquitous event type.
-// 	type Event = Event;
-// }
-
-// impl sudo.Trait for Runtime {
-// 	/// The uniquitous event type.
-// 	type Event = Event;
-// 	type Proposal = Call;
-// }
-
-type Runtime runtimemodule.Runtime
-
-type TypeParams struct{}
-
-// TODO: implement
-func (_ TypeParams) NewHash(b byte) srprimitives.HashOutput                    { return nil }
-func (_ TypeParams) BlockNumber(uint64) srprimitives.BlockNumber               { return nil }
-func (_ TypeParams) DecodeDigestItem(pd codec.Decoder) srprimitives.DigestItem { return nil }
-func (_ TypeParams) ZeroIndex() srprimitives.Index                             { return nil }
-func (_ TypeParams) DecodeEvent(pd codec.Decoder) support.Event                { return nil }
-func (_ TypeParams) EmptyHash() srprimitives.HashOutput                        { return nil }
-func (_ TypeParams) DefaultContext() interface{}                               { return nil }
-
-var runtime = Runtime{TypeParams: TypeParams{}}
-
-var executive = executivemodule.Executive{
-	runtime.System,
-	nil, // Todo: payment
-	nil, // Todo: onfinalise
+// The modules of the runtime, configured as their Trait implementations in the Rust node template.
+// References between the modules are set up in init().
+
+var system = systemmodule.Module{Version: VERSION}
+
+var timestamp = timestampmodule.Module{}
+
+var consensus = consensusmodule.Module{
+	System:             &system,
+	AuthorityIdFactory: authorityIdFactory,
+	// The aura module handles offline-reports internally
+	// rather than using an explicit report system.
+	InherentOfflineReport: consensusmodule.NoOfflineReport{},
 }
 
-// construct_runtime!(
-// 	pub enum Runtime with Log(InternalLog: DigestItem<Hash, Ed25519AuthorityId>) where
-// 		Block = Block,
-// 		NodeBlock = opaque::Block,
-// 		UncheckedExtrinsic = UncheckedExtrinsic
-// 	{
-// 		System: system::{default, Log(ChangesTrieRoot)},
-// 		Timestamp: timestamp::{Module, Call, Storage, Config<T>, Inherent},
-// 		Consensus: consensus::{Module, Call, Storage, Config<T>, Log(AuthoritiesChange), Inherent},
-// 		Aura: aura::{Module},
-// 		Indices: indices,
-// 		Balances: balances,
-// 		Sudo: sudo,
-// 	}
-// );
-
-// /// The type used as a helper for interpreting the sender of transactions.
-// type Context = system.ChainContext<Runtime>;
-// /// The address format for describing accounts.
-// type Address = <Indices as StaticLookup>.Source;
-// /// Block header type as expected by this runtime.
-// type Header = generic.Header<BlockNumber, BlakeTwo256, Log>;
-// /// Block type as expected by this runtime.
-// type Block = generic.Block<Header, UncheckedExtrinsic>;
-// /// BlockId type as expected by this runtime.
-// type BlockId = generic.BlockId<Block>;
-// /// Unchecked extrinsic type as expected by this runtime.
-// type UncheckedExtrinsic = generic.UncheckedMortalCompactExtrinsic<Address, Nonce, Call, Ed25519Signature>;
-// /// Extrinsic type that has already been checked.
-// type CheckedExtrinsic = generic.CheckedExtrinsic<AccountId, Nonce, Call>;
-// /// Executive: handles dispatch to the various modules.
-// type Executive = executive.Executive<Runtime, Block, Context, Balances, AllModules>;
+var aura = auramodule.Module{Timestamp: &timestamp}
+
+var indices = indicesmodule.Module{
+	System: &system,
+	/// Use the standard means of resolving an index hint from an id.
+	ResolveHint: indicesmodule.SimpleResolveHint{},
+}
+
+var balances = balancesmodule.Module{System: &system}
+
+var sudo = sudomodule.Module{System: &system}
+
+/// Storage migrations of the modules, registered in init() when the storage layout changes.
+var migrations migration.Registry
+
+var runtime = runtimemodule.Runtime{TypeParams: TypeParams{}, Migrations: &migrations}
+
+/// Executive: handles dispatch to the various modules.
+var executive executivemodule.Executive
+
+func init() {
+	timestamp.OnTimestampSet = &aura
+	/// Determine whether an account is dead.
+	indices.IsDeadAccount = &balances
+	/// What to do if a new account is created.
+	balances.OnNewAccount = &indices
+	/// The lookup mechanism to get account ID from whatever is passed in dispatchers.
+	balances.Lookup = &indices
+	sudo.Lookup = &indices
+	sudo.Proposal = &runtime
+
+	// construct_runtime!: the order of registration determines the indices
+	// of the modules in the "Call" and "Event" enums.
+	r := &runtime
+	runtimemodule.RegisterModule(r, &system, runtimemodule.DefaultPlus(runtimemodule.ModuleFlags{Name: "System", Log: "ChangesTrieRoot"}))
+	runtimemodule.RegisterModule(r, &timestamp, runtimemodule.ModuleFlags{Name: "Timestamp", Module: true, Call: true, Storage: true, Config: true})
+	runtimemodule.RegisterModule(r, &consensus, runtimemodule.ModuleFlags{Name: "Consensus", Module: true, Call: true, Storage: true, Config: true, Log: "AuthoritiesChange"})
+	runtimemodule.RegisterModule(r, &aura, runtimemodule.ModuleFlags{Name: "Aura", Module: true})
+	runtimemodule.RegisterModule(r, &indices, runtimemodule.DefaultPlus(runtimemodule.ModuleFlags{Name: "Indices"}))
+	runtimemodule.RegisterModule(r, &balances, runtimemodule.DefaultPlus(runtimemodule.ModuleFlags{Name: "Balances"}))
+	runtimemodule.RegisterModule(r, &sudo, runtimemodule.DefaultPlus(runtimemodule.ModuleFlags{Name: "Sudo"}))
+
+	executive = executivemodule.Executive{system, &balances, r}
+}
 
 // Implement our runtime API endpoints. This is just a bunch of proxying.
 
-//go:export "Core_version"
-func version() srversion.RuntimeVersion {
-	return VERSION
+//go:export Core_version
+func version(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		return VERSION
+	})
 }
 
-// TODO: implement consensus
-// //go:export "Core_authorities"
-// func authorities() []primitives.Ed25519AuthorityId {
-// 	return Consensus.authorities()
-// }
+//go:export Core_authorities
+func authorities(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		return consensus.Authorities()
+	})
+}
+
+//go:export Core_execute_block
+func execute_block(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		var block srprimitives.Block
+		block.ParityDecode(pd, TypeParams{})
+		executive.ExecuteBlock(&block)
+		return nil
+	})
+}
 
-//go:export "Core_execute_block"
-func execute_block(block srprimitives.Block) {
-	executive.ExecuteBlock(&block)
+//go:export Core_initialise_block
+func initialise_block(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		var header srprimitives.Header
+		header.ParityDecode(pd, TypeParams{})
+		executive.InitialiseBlock(&header)
+		return nil
+	})
 }
 
-//go:export "Core_initialise_block"
-func initialise_block(header srprimitives.Header) {
-	executive.InitialiseBlock(&header)
+//go:export Metadata_metadata
+func metadata(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		// OpaqueMetadata: the encoded metadata as a byte slice
+		return gohelpers.ByteSlice(codec.ToBytes(runtime.GetMetadata()))
+	})
 }
 
-//go:export "Metadata_metadata"
-func metadata() metadatamodule.RuntimeMetadata {
-	return (*runtimemodule.Runtime)(&runtime).GetMetadata()
+//go:export BlockBuilder_apply_extrinsic
+func apply_extrinsic(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		return executive.ApplyExtrinsic(TypeParams{}.DecodeExtrinsic(pd))
+	})
 }
 
-//go:export "BlockBuilder_apply_extrinsic"
-func apply_extrinsic(extrinsic srprimitives.UncheckedExtrinsic) srprimitives.ApplyResult {
-	return executive.ApplyExtrinsic(&extrinsic)
+//go:export BlockBuilder_finalise_block
+func finalise_block(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		header := executive.FinaliseBlock()
+		return &header
+	})
 }
 
-//go:export "BlockBuilder_finalise_block"
-func finalise_block() srprimitives.Header {
-	return executive.FinaliseBlock()
+//go:export BlockBuilder_inherent_extrinsics
+func inherent_extrinsics(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		var inherentData inherents.InherentData
+		inherentData.ParityDecode(pd)
+		extrinsics := inherentData.CreateExtrinsics(&runtime)
+		return EncodeFunc(func(pe codec.Encoder) {
+			pe.EncodeCollection(len(extrinsics), func(i int) { extrinsics[i].ParityEncode(pe) })
+		})
+	})
 }
 
-//go:export "BlockBuilder_inherent_extrinsics"
-func inherent_extrinsics(data inherents.InherentData) []srprimitives.Extrinsic {
-	return data.CreateExtrinsics((*runtimemodule.Runtime)(&runtime))
+//go:export BlockBuilder_check_inherents
+func check_inherents(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		var block srprimitives.Block
+		block.ParityDecode(pd, TypeParams{})
+		var inherentData inherents.InherentData
+		inherentData.ParityDecode(pd)
+		return inherentData.CheckExtrinsics(&runtime, block)
+	})
 }
 
-//go:export "BlockBuilder_check_inherents"
-func check_inherents(block srprimitives.Block, data inherents.InherentData) inherents.CheckInherentsResult {
-	return data.CheckExtrinsics((*runtimemodule.Runtime)(&runtime), block)
+//go:export BlockBuilder_random_seed
+func random_seed(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		return system.RandomSeedStore.Get().(srprimitives.HashOutput)
+	})
 }
 
-//go:export "BlockBuilder_random_seed"
-func random_seed() srprimitives.HashOutput {
-	return runtime.System.RandomSeedStore.Get().(srprimitives.HashOutput)
+//go:export TaggedTransactionQueue_validate_transaction
+func validate_transaction(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		return executive.ValidateTransaction(TypeParams{}.DecodeExtrinsic(pd)).EncodeableEnum()
+	})
 }
 
-//go:export "TaggedTransactionQueue_validate_transaction"
-func validate_transaction(tx srprimitives.Extrinsic) srprimitives.TransactionValidity {
-	return executive.ValidateTransaction(tx)
+//go:export OffchainWorkerApi_offchain_worker
+func offchain_worker(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		number := TypeParams{}.BlockNumber(0)
+		number.ParityDecode(pd)
+		executive.GenerateExtrinsics(number)
+		return nil
+	})
 }
 
-// TODO: implement aura
-// //go:export "AuraApi_x"
-// func slot_duration() uint64 {
-// 	return Aura.SlotDuration()
-// }
+//go:export AuraApi_slot_duration
+func slot_duration(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		return Uint64(aura.SlotDuration())
+	})
+}
+
+// Not a runtime API of Substrate: builds the genesis storage of the local testnet (see TestnetGenesis)
+// in a dry run, for the raw genesis of a chain spec. Call it in a wasm executor with the code,
+// the initial authorities, the endowed accounts and the root key, it returns the (key, value) pairs.
+//
+//go:export GenesisConfig_testnet_storage
+func testnet_storage(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd codec.Decoder) codec.Encodeable {
+		code := pd.DecodeByteSlice()
+		var initialAuthorities []SessionKey
+		pd.DecodeCollection(
+			func(n int) { initialAuthorities = make([]SessionKey, n) },
+			func(i int) { initialAuthorities[i].ParityDecode(pd) },
+		)
+		var endowedAccounts []AccountId
+		pd.DecodeCollection(
+			func(n int) { endowedAccounts = make([]AccountId, n) },
+			func(i int) { endowedAccounts[i].ParityDecode(pd) },
+		)
+		var rootKey AccountId
+		rootKey.ParityDecode(pd)
+
+		config := TestnetGenesis(code, initialAuthorities, endowedAccounts, rootKey)
+		changes := srio.DryRun(config.BuildStorage).Changes
+		return EncodeFunc(func(pe codec.Encoder) {
+			var pairs []srio.StorageChange
+			for _, c := range changes {
+				if c.Value != nil {
+					pairs = append(pairs, c)
+				}
+			}
+			pe.EncodeCollection(len(pairs), func(i int) {
+				pe.EncodeByteSlice(pairs[i].Key)
+				pe.EncodeByteSlice(pairs[i].Value)
+			})
+		})
+	})
+}
 
+// TODO: learn to build WASM modules in TinyGo without main()
 func main() {}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	balancesmodule "github.com/Joystream/tinygo-wasm-substrate/srml/balances"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	systemmodule "github.com/Joystream/tinygo-wasm-substrate/srml/system"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+/// Alias to Ed25519 pubkey that identifies an account on the chain.
+type AccountId = primitives.H256
+
+/// A hash of some data used by the chain.
+type Hash = primitives.H256
+
+/// Index of a block number in the chain: a u64, see TypeParams.BlockNumber.
+type BlockNumber = gohelpers.Uint
+
+/// The identifier we use to refer to authorities.
+type SessionKey = primitives.Ed25519AuthorityId
+
+/// Index of an account's extrinsic in the chain: a u64, see TypeParams.Index.
+type Nonce = gohelpers.Uint
+
+/// The type for recording an account's balance.
+type Balance gohelpers.U128
+
+func (b Balance) ParityEncode(pe codec.Encoder) {
+	gohelpers.U128(b).ParityEncode(pe)
+}
+
+func (b Balance) ParityEncodeCompact(pe codec.Encoder) {
+	gohelpers.U128(b).ParityEncodeCompact(pe)
+}
+
+func (b Balance) IsZero() bool {
+	return gohelpers.U128(b).IsZero()
+}
+
+func (b Balance) LessThan(o balancesmodule.Balance) bool {
+	return gohelpers.U128(b).LessThan(gohelpers.U128(o.(Balance)))
+}
+
+func (b Balance) CheckedAdd(o balancesmodule.Balance) (bool, balancesmodule.Balance) {
+	ok, r := gohelpers.U128(b).CheckedAdd(gohelpers.U128(o.(Balance)))
+	if !ok {
+		return false, nil
+	}
+	return true, Balance(r)
+}
+
+func (b Balance) CheckedSub(o balancesmodule.Balance) (bool, balancesmodule.Balance) {
+	ok, r := gohelpers.U128(b).CheckedSub(gohelpers.U128(o.(Balance)))
+	if !ok {
+		return false, nil
+	}
+	return true, Balance(r)
+}
+
+func (b Balance) SaturatingAdd(o balancesmodule.Balance) balancesmodule.Balance {
+	return Balance(gohelpers.U128(b).SaturatingAdd(gohelpers.U128(o.(Balance))))
+}
+
+func (b Balance) SaturatingSub(o balancesmodule.Balance) balancesmodule.Balance {
+	return Balance(gohelpers.U128(b).SaturatingSub(gohelpers.U128(o.(Balance))))
+}
+
+func (b Balance) SaturatingMul(o balancesmodule.Balance) balancesmodule.Balance {
+	return Balance(gohelpers.U128(b).SaturatingMul(gohelpers.U128(o.(Balance))))
+}
+
+func (b Balance) String() string {
+	return gohelpers.U128(b).String()
+}
+
+func authorityIdFactory() srprimitives.AuthorityId { return &SessionKey{} }
+
+// Implements the type parameters of all the modules in the runtime:
+// support.TypeParamsFactory, srprimitives.BlockTypeParamsFactory,
+// srprimitives.ExtrinsicTypeParamsFactory and balances.BalanceFactory
+type TypeParams struct{}
+
+func (_ TypeParams) NewHash(b byte) srprimitives.HashOutput {
+	var h Hash
+	for i := range h {
+		h[i] = b
+	}
+	return &h
+}
+
+func (_ TypeParams) NewHashOutput() srprimitives.HashOutput {
+	return &Hash{}
+}
+
+func (_ TypeParams) BlockNumber(i uint64) srprimitives.BlockNumber {
+	return gohelpers.NewUint64(i)
+}
+
+func (_ TypeParams) DecodeDigestItem(pd codec.Decoder) srprimitives.DigestItem {
+	return runtime.DecodeLog(pd, authorityIdFactory)
+}
+
+func (_ TypeParams) ZeroIndex() srprimitives.Index {
+	return gohelpers.NewUint64(0)
+}
+
+func (_ TypeParams) Index(i uint64) srprimitives.Index {
+	return gohelpers.NewUint64(i)
+}
+
+func (_ TypeParams) DecodeAccountId(pd codec.Decoder) srprimitives.AccountId {
+	var a AccountId
+	a.ParityDecode(pd)
+	return &a
+}
+
+func (_ TypeParams) DecodeEvent(pd codec.Decoder) support.Event {
+	return runtime.DecodeEvent(pd)
+}
+
+/// The hashing algorithm used.
+func (_ TypeParams) Hasher() srprimitives.Hasher {
+	return srprimitives.BlakeTwo256{}
+}
+
+/// The type used as a helper for interpreting the sender of transactions.
+func (_ TypeParams) DefaultContext() interface{} {
+	return systemmodule.ChainContext{System: &system, AddressLookup: &indices}
+}
+
+/// Unchecked extrinsic type as expected by this runtime.
+func (_ TypeParams) DecodeExtrinsic(pd codec.Decoder) srprimitives.Extrinsic {
+	return srprimitives.DecodeUncheckedMortalCompactExtrinsic(pd, TypeParams{})
+}
+
+/// The address format for describing accounts.
+func (_ TypeParams) DecodeAddress(pd codec.Decoder) srprimitives.Address {
+	return indices.DecodeAddress(pd)
+}
+
+func (_ TypeParams) DecodeSignature(pd codec.Decoder) srprimitives.Verify {
+	var s srprimitives.Ed25519Signature
+	s.ParityDecode(pd)
+	return &s
+}
+
+func (_ TypeParams) DecodeCall(pd codec.Decoder) srprimitives.Callable {
+	return runtime.DecodeCall(pd)
+}
+
+func (_ TypeParams) Balance(i uint64) balancesmodule.Balance {
+	return Balance(gohelpers.U128From64(i))
+}
+
+func (_ TypeParams) DecodeBalance(pd codec.Decoder) balancesmodule.Balance {
+	var b gohelpers.U128
+	b.ParityDecode(pd)
+	return Balance(b)
+}
+
+func (_ TypeParams) DecodeCompactBalance(pd codec.Decoder) balancesmodule.Balance {
+	var b gohelpers.U128
+	b.ParityDecodeCompact(pd)
+	return Balance(b)
+}
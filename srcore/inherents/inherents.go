@@ -2,6 +2,7 @@ package inherents
 
 import (
 	"bytes"
+	"sort"
 
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
 	runtimemodule "github.com/Joystream/tinygo-wasm-substrate/srml/support/runtime"
@@ -17,6 +18,38 @@ type InherentData struct {
 	data map[InherentIdentifier][]byte
 }
 
+func NewInherentData() InherentData {
+	return InherentData{data: make(map[InherentIdentifier][]byte)}
+}
+
+/// Encoded as the list of the identifiers (in ascending order), followed by the list of the data.
+func (i InherentData) ParityEncode(pe codec.Encoder) {
+	ids := make([]InherentIdentifier, 0, len(i.data))
+	for id := range i.data {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return bytes.Compare(ids[a][:], ids[b][:]) < 0 })
+	pe.EncodeCollection(len(ids), func(n int) { pe.Write(ids[n][:]) })
+	pe.EncodeCollection(len(ids), func(n int) { pe.EncodeByteSlice(i.data[ids[n]]) })
+}
+
+func (i *InherentData) ParityDecode(pd codec.Decoder) {
+	var ids []InherentIdentifier
+	pd.DecodeCollection(
+		func(n int) { ids = make([]InherentIdentifier, n) },
+		func(n int) { pd.Read(ids[n][:]) },
+	)
+	i.data = make(map[InherentIdentifier][]byte)
+	pd.DecodeCollection(
+		func(n int) {
+			if n != len(ids) {
+				panic("Number of inherent identifiers and values must match")
+			}
+		},
+		func(n int) { i.data[ids[n]] = pd.DecodeByteSlice() },
+	)
+}
+
 /// Put data for an inherent into the internal storage.
 ///
 /// # Return
@@ -77,6 +110,15 @@ type CheckInherentError interface {
 	codec.Encodeable
 }
 
+/// Auxiliary to make any given error message resolve to `IsFatalError() == true`.
+type MakeFatalError string
+
+func (_ MakeFatalError) IsFatalError() bool { return true }
+
+func (e MakeFatalError) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(string(e))
+}
+
 type ProvideInherent interface {
 	InherentIdentifier() InherentIdentifier
 	CreateInherent(*InherentData) srprimitives.Callable                    // nillable
@@ -92,8 +134,14 @@ type CheckInherentsResult struct {
 	Errors InherentData
 }
 
+func (c CheckInherentsResult) ParityEncode(pe codec.Encoder) {
+	pe.EncodeBool(c.Okay)
+	pe.EncodeBool(c.FatalError)
+	c.Errors.ParityEncode(pe)
+}
+
 func NewCheckInherentsResult() CheckInherentsResult {
-	return CheckInherentsResult{Okay: true, Errors: InherentData{data: make(map[InherentIdentifier][]byte)}}
+	return CheckInherentsResult{Okay: true, Errors: NewInherentData()}
 }
 
 /// Put an error into the result.
@@ -127,8 +175,8 @@ func (i *InherentData) CreateExtrinsics(runtime *runtimemodule.Runtime) []srprim
 		if ok {
 			inherent := mi.CreateInherent(i)
 			if inherent != nil {
-				// TODO: figure out how wrapping into a Call works
-				inherents = append(inherents, &srprimitives.UncheckedExtrinsic{Function: inherent})
+				call := runtime.WrapCall(m.Module, inherent)
+				inherents = append(inherents, &srprimitives.UncheckedExtrinsic{Function: call})
 			}
 		}
 	}
@@ -148,12 +196,12 @@ func (i *InherentData) CheckExtrinsics(runtime *runtimemodule.Runtime, block srp
 		m := runtime.ModuleForCall(function)
 		mi, ok := m.(ProvideInherent)
 		if ok {
-			err := mi.CheckInherent(function, i)
+			err := mi.CheckInherent(function.ModuleCall(), i)
 			if err != nil {
 				result.PutError(mi.InherentIdentifier(), err) // Panic if more than one fatal error
-			}
-			if err.IsFatalError() {
-				return result
+				if err.IsFatalError() {
+					return result
+				}
 			}
 		}
 	}
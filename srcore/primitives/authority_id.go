@@ -9,3 +9,7 @@ type Ed25519AuthorityId [32]byte
 func (a *Ed25519AuthorityId) ParityDecode(pd paritycodec.Decoder) {
 	pd.Read(a[:])
 }
+
+func (a *Ed25519AuthorityId) ParityEncode(pe paritycodec.Encoder) {
+	pe.Write(a[:])
+}
@@ -0,0 +1,63 @@
+package srio
+
+import "bytes"
+
+// Dry runs: the storage changes are recorded in an overlay instead of
+// being written to the storage of the host.
+
+/// A change of a storage value, recorded by DryRun.
+type StorageChange struct {
+	Key []byte
+	/// The new value, nil if the value is removed
+	Value []byte
+}
+
+/// The storage changes recorded by DryRun, in the order of their first write.
+// The changes are searched linearly, as maps are not well supported by TinyGo
+// and dry runs make few changes.
+type Overlay struct {
+	Changes []StorageChange
+}
+
+var overlay *Overlay
+
+func (o *Overlay) find(key []byte) int {
+	for i := range o.Changes {
+		if bytes.Equal(o.Changes[i].Key, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o *Overlay) set(key []byte, value []byte) {
+	i := o.find(key)
+	if i >= 0 {
+		o.Changes[i].Value = value
+		return
+	}
+	o.Changes = append(o.Changes, StorageChange{append([]byte{}, key...), value})
+}
+
+// Returns whether the key was changed, and the changed value (if any)
+func (o *Overlay) get(key []byte) (bool, StorageChange) {
+	i := o.find(key)
+	if i < 0 {
+		return false, StorageChange{}
+	}
+	return true, o.Changes[i]
+}
+
+/// Executes f, recording its storage changes instead of writing them to the storage
+/// of the host. The reads done by f observe its own changes, but the roots (e.g. StorageRoot)
+/// computed by the host do not. Dry runs can not be nested.
+func DryRun(f func()) *Overlay {
+	if overlay != nil {
+		panic("Dry runs can not be nested")
+	}
+	o := &Overlay{}
+	overlay = o
+	defer func() { overlay = nil }()
+	f()
+	return o
+}
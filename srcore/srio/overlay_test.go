@@ -0,0 +1,81 @@
+package srio
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The host storage is not available natively, the overlay is tested on its own
+
+func TestOverlayReadsItsWrites(t *testing.T) {
+	o := &Overlay{}
+	if changed, _ := o.get([]byte("a")); changed {
+		t.Error("an unchanged key")
+	}
+	key := []byte("a")
+	o.set(key, []byte{1})
+	o.set([]byte("b"), []byte{2})
+	o.set([]byte("a"), []byte{3})
+	// The key is copied
+	key[0] = 'z'
+	if changed, c := o.get([]byte("a")); !changed || !bytes.Equal(c.Value, []byte{3}) {
+		t.Errorf("a: %v, %v", changed, c)
+	}
+	// The changes are in the order of their first write
+	if len(o.Changes) != 2 || string(o.Changes[0].Key) != "a" || string(o.Changes[1].Key) != "b" {
+		t.Errorf("changes %v", o.Changes)
+	}
+}
+
+func TestOverlayKill(t *testing.T) {
+	o := &Overlay{}
+	o.set([]byte("a"), []byte{1})
+	o.set([]byte("a"), nil)
+	// A removed value is a change, without a value (UnhashedGet then reports no value)
+	if changed, c := o.get([]byte("a")); !changed || c.Value != nil {
+		t.Errorf("a: %v, %v", changed, c)
+	}
+	o.set([]byte("a"), []byte{})
+	if changed, c := o.get([]byte("a")); !changed || c.Value == nil {
+		t.Errorf("an empty value is not a removal: %v, %v", changed, c)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	o := DryRun(func() {
+		if overlay == nil {
+			t.Error("the overlay should be set during the dry run")
+		}
+		overlay.set([]byte("a"), []byte{1})
+	})
+	if overlay != nil {
+		t.Error("the overlay should be unset after the dry run")
+	}
+	if len(o.Changes) != 1 || string(o.Changes[0].Key) != "a" {
+		t.Errorf("changes %v", o.Changes)
+	}
+}
+
+func TestNestedDryRunPanics(t *testing.T) {
+	panicked := false
+	DryRun(func() {
+		defer func() { panicked = recover() != nil }()
+		DryRun(func() {})
+	})
+	if !panicked {
+		t.Error("a nested dry run should panic")
+	}
+	if overlay != nil {
+		t.Error("the overlay should be unset after the dry run")
+	}
+}
+
+func TestDryRunUnsetsTheOverlayOnPanic(t *testing.T) {
+	func() {
+		defer func() { recover() }()
+		DryRun(func() { panic("failed") })
+	}()
+	if overlay != nil {
+		t.Error("the overlay should be unset after a panic")
+	}
+}
@@ -29,6 +29,9 @@ func ext_clear_storage(key_data *byte, key_len uintptr)
 //go:export ext_blake2_256
 func ext_blake2_256(data *byte, len uintptr, out *byte)
 
+//go:export ext_keccak_256
+func ext_keccak_256(data *byte, len uintptr, out *byte)
+
 //go:export ext_twox_128
 func ext_twox_128(data *byte, len uintptr, out *byte)
 
@@ -120,10 +120,23 @@ func EnumeratedTrieRootBlake256ForByteSlices(values [][]byte) [32]byte {
 }
 
 func UnhashedPut(key []byte, value []byte) {
+	if overlay != nil {
+		overlay.set(key, append([]byte{}, value...))
+		return
+	}
 	ext_set_storage(GetOffset(key), GetLen(key), GetOffset(value), GetLen(value))
 }
 
 func UnhashedGet(key []byte) (bool, []byte) {
+	if overlay != nil {
+		changed, change := overlay.get(key)
+		if changed && change.Value == nil {
+			return false, []byte{}
+		}
+		if changed {
+			return true, change.Value
+		}
+	}
 	var valueLen uintptr
 	valuePtr := ext_get_allocated_storage(GetOffset(key), GetLen(key), &valueLen)
 	if valueLen == math.MaxUint32 {
@@ -133,6 +146,10 @@ func UnhashedGet(key []byte) (bool, []byte) {
 }
 
 func UnhashedKill(key []byte) {
+	if overlay != nil {
+		overlay.set(key, nil)
+		return
+	}
 	ext_clear_storage(GetOffset(key), GetLen(key))
 }
 
@@ -160,6 +177,12 @@ func Twox256(v []byte) []byte {
 	return res[:]
 }
 
+func Keccak256(v []byte) []byte {
+	var res [32]byte
+	ext_keccak_256(GetOffset(v), GetLen(v), &res[0])
+	return res[:]
+}
+
 func Blake256(v []byte) []byte {
 	var res [32]byte
 	ext_blake2_256(GetOffset(v), GetLen(v), &res[0])
@@ -0,0 +1,419 @@
+package srio
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+)
+
+// The Patricia-Merkle trie of Substrate (substrate-trie, with the node encoding of its
+// NodeCodec), to compute the roots of the tries which the host does not compute,
+// and to verify the proofs of storage values, e.g. from other chains.
+//
+// A node is encoded as a header byte followed by its contents:
+// - empty trie: the header only
+// - leaf: the nibbles of the partial key (an odd first nibble alone, then in pairs), the value
+// - extension: the nibbles of the partial key, the reference to the child
+// - branch: a bitmap of the children, the value (if any), the references to the children
+// The values and the references are encoded as byte slices. A child which encodes to less
+// than 32 bytes is inlined, otherwise it is referred to by the hash of its encoding.
+
+const (
+	trieEmpty           = 0
+	trieLeafOffset      = 1
+	trieLeafBig         = 127
+	trieExtensionOffset = 128
+	trieExtensionBig    = 253
+	trieBranchNoValue   = 254
+	trieBranchWithValue = 255
+	trieHashLength      = 32
+)
+
+/// A key and its value in a trie
+type TriePair struct {
+	Key   []byte
+	Value []byte
+}
+
+/// The Blake2-256 root of the trie of the values keyed by their (compact-encoded) indices,
+/// computed by the host. As in sr-io, this is the same as the enumerated trie root.
+func OrderedTrieRoot(values [][]byte) [32]byte {
+	return EnumeratedTrieRootBlake256ForByteSlices(values)
+}
+
+/// The Blake2-256 root of the trie of the pairs. Of the pairs with the same key, the last one counts.
+/// The host has no function for the tries of arbitrary pairs (sr-io leaves it unimplemented),
+/// so the trie is built here, hashing the nodes with the host.
+func TrieRoot(pairs []TriePair) [32]byte {
+	var res [32]byte
+	copy(res[:], trieRoot(pairs, Blake256))
+	return res
+}
+
+/// The root of the trie of the pairs, with the nodes hashed by hash (instead of Blake2-256),
+/// for the hashers of the runtimes which the host does not support, e.g. Keccak-256.
+func TrieRootWithHasher(pairs []TriePair, hash func([]byte) []byte) []byte {
+	return trieRoot(pairs, hash)
+}
+
+/// The root of the trie of the values keyed by their compact-encoded indices, with the nodes
+/// hashed by hash (see TrieRootWithHasher).
+func OrderedTrieRootWithHasher(values [][]byte, hash func([]byte) []byte) []byte {
+	pairs := make([]TriePair, len(values))
+	for i, v := range values {
+		pairs[i] = TriePair{appendCompactLen(nil, i), v}
+	}
+	return trieRoot(pairs, hash)
+}
+
+/// Verifies a proof of the value under the key in the Blake2-256 trie with the given root.
+/// The proof is the set of the encoded nodes on the path to the key, in any order,
+/// as in the storage read proofs of Substrate.
+/// Returns whether the key is in the trie, and its value. Fails if the proof is incomplete.
+func VerifyTrieProof(root []byte, key []byte, proof [][]byte) (bool, []byte, error) {
+	return verifyTrieProof(root, key, proof, Blake256)
+}
+
+func keyToNibbles(key []byte) []byte {
+	nibbles := make([]byte, 2*len(key))
+	for i, b := range key {
+		nibbles[2*i] = b >> 4
+		nibbles[2*i+1] = b & 0x0f
+	}
+	return nibbles
+}
+
+// Building tries, as trie_root in substrate-trie (with triehash)
+
+type trieEntries []TriePair // with the keys as nibbles
+
+func (t trieEntries) Len() int           { return len(t) }
+func (t trieEntries) Less(i, j int) bool { return bytes.Compare(t[i].Key, t[j].Key) < 0 }
+func (t trieEntries) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+func trieRoot(pairs []TriePair, hash func([]byte) []byte) []byte {
+	entries := make(trieEntries, len(pairs))
+	for i, p := range pairs {
+		entries[i] = TriePair{keyToNibbles(p.Key), p.Value}
+	}
+	sort.Stable(entries)
+	// Keep the last of the pairs with the same key
+	unique := entries[:0]
+	for i := range entries {
+		if len(unique) > 0 && bytes.Equal(unique[len(unique)-1].Key, entries[i].Key) {
+			unique[len(unique)-1] = entries[i]
+		} else {
+			unique = append(unique, entries[i])
+		}
+	}
+	return hash(buildTrieNode(unique, 0, hash))
+}
+
+func encodeTrieBytes(out []byte, data []byte) []byte {
+	out = appendCompactLen(out, len(data))
+	return append(out, data...)
+}
+
+func appendCompactLen(out []byte, n int) []byte {
+	switch {
+	case n < 1<<6:
+		return append(out, byte(n<<2))
+	case n < 1<<14:
+		return append(out, byte(n<<2|1), byte(n>>6))
+	case n < 1<<30:
+		return append(out, byte(n<<2|2), byte(n>>6), byte(n>>14), byte(n>>22))
+	}
+	return append(out, 3, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+// The header and the partial key of a leaf or an extension
+func encodeTriePartial(out []byte, nibbles []byte, offset byte, big byte) []byte {
+	threshold := int(big - offset)
+	if len(nibbles) >= threshold {
+		out = append(out, big, byte(len(nibbles)-threshold))
+	} else {
+		out = append(out, offset+byte(len(nibbles)))
+	}
+	if len(nibbles)%2 == 1 {
+		out = append(out, nibbles[0])
+	}
+	for i := len(nibbles) % 2; i < len(nibbles); i += 2 {
+		out = append(out, nibbles[i]<<4|nibbles[i+1])
+	}
+	return out
+}
+
+// Appends the reference to a child: the child itself if it is short, its hash otherwise
+func appendTrieChild(out []byte, child []byte, hash func([]byte) []byte) []byte {
+	if len(child) < trieHashLength {
+		return encodeTrieBytes(out, child)
+	}
+	return encodeTrieBytes(out, hash(child))
+}
+
+// Encodes the node of the sorted entries, the first cursor nibbles of their keys being consumed
+func buildTrieNode(entries trieEntries, cursor int, hash func([]byte) []byte) []byte {
+	switch len(entries) {
+	case 0:
+		return []byte{trieEmpty}
+	case 1:
+		out := encodeTriePartial(nil, entries[0].Key[cursor:], trieLeafOffset, trieLeafBig)
+		return encodeTrieBytes(out, entries[0].Value)
+	}
+
+	// Count the number of nibbles shared by all keys
+	key := entries[0].Key
+	shared := len(key)
+	for _, e := range entries[1:] {
+		n := 0
+		for n < shared && n < len(e.Key) && e.Key[n] == key[n] {
+			n++
+		}
+		shared = n
+	}
+	if shared > cursor {
+		out := encodeTriePartial(nil, key[cursor:shared], trieExtensionOffset, trieExtensionBig)
+		return appendTrieChild(out, buildTrieNode(entries, shared, hash), hash)
+	}
+
+	// A branch, with the value of the key which ends here, if any
+	begin := 0
+	hasValue := len(key) == cursor
+	if hasValue {
+		begin = 1
+	}
+	var counts [16]int
+	var bitmap uint16
+	for i, j := 0, begin; i < 16; i++ {
+		for j < len(entries) && int(entries[j].Key[cursor]) == i {
+			counts[i]++
+			j++
+		}
+		if counts[i] > 0 {
+			bitmap |= 1 << uint(i)
+		}
+	}
+	header := byte(trieBranchNoValue)
+	if hasValue {
+		header = trieBranchWithValue
+	}
+	out := []byte{header, byte(bitmap), byte(bitmap >> 8)}
+	if hasValue {
+		out = encodeTrieBytes(out, entries[0].Value)
+	}
+	for i := 0; i < 16; i++ {
+		if counts[i] > 0 {
+			child := buildTrieNode(entries[begin:begin+counts[i]], cursor+1, hash)
+			out = appendTrieChild(out, child, hash)
+			begin += counts[i]
+		}
+	}
+	return out
+}
+
+// Decoding nodes and verifying proofs
+
+var errBadTrieNode = errors.New("invalid trie node")
+
+const (
+	trieNodeEmpty byte = iota
+	trieNodeLeaf
+	trieNodeExtension
+	trieNodeBranch
+)
+
+type trieNode struct {
+	kind     byte
+	partial  []byte // nibbles
+	hasValue bool
+	value    []byte
+	// The references to the children (only the first one for an extension), nil if none
+	children [16][]byte
+}
+
+type trieReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *trieReader) take(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, errBadTrieNode
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *trieReader) byte() (byte, error) {
+	b, err := r.take(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (r *trieReader) bytes() ([]byte, error) {
+	b, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	var n int
+	switch b & 3 {
+	case 0:
+		n = int(b >> 2)
+	case 1:
+		rest, err := r.take(1)
+		if err != nil {
+			return nil, err
+		}
+		n = int(b>>2) | int(rest[0])<<6
+	case 2:
+		rest, err := r.take(3)
+		if err != nil {
+			return nil, err
+		}
+		n = int(b>>2) | int(rest[0])<<6 | int(rest[1])<<14 | int(rest[2])<<22
+	default:
+		if b != 3 {
+			return nil, errBadTrieNode
+		}
+		rest, err := r.take(4)
+		if err != nil {
+			return nil, err
+		}
+		n = int(rest[0]) | int(rest[1])<<8 | int(rest[2])<<16 | int(rest[3])<<24
+	}
+	return r.take(n)
+}
+
+func (r *trieReader) partial(count int) ([]byte, error) {
+	packed, err := r.take((count + 1) / 2)
+	if err != nil {
+		return nil, err
+	}
+	nibbles := keyToNibbles(packed)
+	// An odd first nibble is alone in its byte
+	return nibbles[count%2:], nil
+}
+
+func decodeTrieNode(data []byte) (trieNode, error) {
+	var node trieNode
+	r := trieReader{data, 0}
+	header, err := r.byte()
+	if err != nil {
+		return node, err
+	}
+	switch {
+	case header == trieEmpty:
+		node.kind = trieNodeEmpty
+		return node, nil
+	case header == trieBranchNoValue || header == trieBranchWithValue:
+		node.kind = trieNodeBranch
+		bitmap, err := r.take(2)
+		if err != nil {
+			return node, err
+		}
+		if header == trieBranchWithValue {
+			node.hasValue = true
+			if node.value, err = r.bytes(); err != nil {
+				return node, err
+			}
+		}
+		for i := uint(0); i < 16; i++ {
+			if (uint16(bitmap[0])|uint16(bitmap[1])<<8)&(1<<i) != 0 {
+				if node.children[i], err = r.bytes(); err != nil {
+					return node, err
+				}
+			}
+		}
+		return node, nil
+	}
+
+	// A leaf or an extension
+	count := 0
+	if header < trieExtensionOffset {
+		node.kind = trieNodeLeaf
+		count = int(header - trieLeafOffset)
+	} else {
+		node.kind = trieNodeExtension
+		count = int(header - trieExtensionOffset)
+	}
+	if header == trieLeafBig || header == trieExtensionBig {
+		extra, err := r.byte()
+		if err != nil {
+			return node, err
+		}
+		count += int(extra)
+	}
+	if node.partial, err = r.partial(count); err != nil {
+		return node, err
+	}
+	if node.kind == trieNodeLeaf {
+		node.hasValue = true
+		node.value, err = r.bytes()
+	} else {
+		node.children[0], err = r.bytes()
+	}
+	return node, err
+}
+
+func verifyTrieProof(root []byte, key []byte, proof [][]byte, hash func([]byte) []byte) (bool, []byte, error) {
+	// The proof nodes are searched linearly, as maps are not well supported by TinyGo
+	hashes := make([][]byte, len(proof))
+	for i := range proof {
+		hashes[i] = hash(proof[i])
+	}
+	lookup := func(h []byte) ([]byte, error) {
+		for i := range hashes {
+			if bytes.Equal(hashes[i], h) {
+				return proof[i], nil
+			}
+		}
+		return nil, errors.New("incomplete trie proof")
+	}
+
+	data, err := lookup(root)
+	if err != nil {
+		return false, nil, err
+	}
+	nibbles := keyToNibbles(key)
+	for {
+		node, err := decodeTrieNode(data)
+		if err != nil {
+			return false, nil, err
+		}
+		var child []byte
+		switch node.kind {
+		case trieNodeEmpty:
+			return false, nil, nil
+		case trieNodeLeaf:
+			if !bytes.Equal(node.partial, nibbles) {
+				return false, nil, nil
+			}
+			return true, node.value, nil
+		case trieNodeExtension:
+			if !bytes.HasPrefix(nibbles, node.partial) {
+				return false, nil, nil
+			}
+			nibbles = nibbles[len(node.partial):]
+			child = node.children[0]
+		case trieNodeBranch:
+			if len(nibbles) == 0 {
+				return node.hasValue, node.value, nil
+			}
+			child = node.children[nibbles[0]]
+			if child == nil {
+				return false, nil, nil
+			}
+			nibbles = nibbles[1:]
+		}
+		if len(child) == trieHashLength {
+			if data, err = lookup(child); err != nil {
+				return false, nil, err
+			}
+		} else {
+			data = child
+		}
+	}
+}
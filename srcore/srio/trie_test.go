@@ -0,0 +1,179 @@
+package srio
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// The host functions are not available natively, the tests hash with Go
+
+func blake2(data []byte) []byte {
+	h := blake2b.Sum256(data)
+	return h[:]
+}
+
+func keccak(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func TestEmptyTrieRoot(t *testing.T) {
+	// The hash of the encoded empty node
+	if root := hex.EncodeToString(trieRoot(nil, blake2)); root != "03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314" {
+		t.Errorf("Blake2 empty root %s", root)
+	}
+	if root := hex.EncodeToString(OrderedTrieRootWithHasher(nil, keccak)); root != "bc36789e7a1e281436464229828f817d6612f7b477d66591ff96a9e064bcc98a" {
+		t.Errorf("Keccak empty root %s", root)
+	}
+}
+
+func TestOrderedTrieRootKeys(t *testing.T) {
+	// The values are keyed by their compact-encoded indices, 64 needs two bytes
+	values := make([][]byte, 70)
+	pairs := make([]TriePair, len(values))
+	for i := range values {
+		values[i] = []byte{byte(i), 1, 2, 3}
+		pairs[i] = TriePair{[]byte{byte(i << 2)}, values[i]}
+		if i >= 64 {
+			pairs[i].Key = []byte{byte(i<<2 | 1), byte(i >> 6)}
+		}
+	}
+	if !bytes.Equal(OrderedTrieRootWithHasher(values, keccak), TrieRootWithHasher(pairs, keccak)) {
+		t.Error("the ordered root differs from the root of the compact-keyed pairs")
+	}
+	if bytes.Equal(OrderedTrieRootWithHasher(values, keccak), OrderedTrieRootWithHasher(values, blake2)) {
+		t.Error("the roots should depend on the hasher")
+	}
+}
+
+// A storage with well known keys, hashed keys, a branch with a value and an empty value.
+// The root and the proofs were computed in Rust with the trie_root algorithm of trie-root
+// and the node encoding of substrate-trie (TrieStream), the proofs being the nodes which
+// a lookup of the key reads from the database (the root, and the nodes referred to by hash).
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func sequence(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+func storagePairs() []TriePair {
+	hashed := func(name string) []byte { return blake2([]byte(name))[:16] }
+	return []TriePair{
+		{[]byte(":code"), sequence(100)},
+		{[]byte(":auth:len"), []byte{1, 0, 0, 0}},
+		{[]byte(":auth:\x00\x00\x00\x00"), unhex("d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")},
+		{[]byte(":extrinsic_index"), []byte{0, 0, 0, 0}},
+		{[]byte(":heappages"), []byte{8, 0, 0, 0, 0, 0, 0, 0}},
+		{hashed("System Number"), []byte{100, 0, 0, 0, 0, 0, 0, 0}},
+		{hashed("Balances TotalIssuance"), unhex("000064a7b3b6e00d0000000000000000")},
+		{hashed("Sudo Key"), unhex("8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48")},
+		{[]byte{0xab, 0xcd}, []byte("branch value")},
+		{[]byte{0xab, 0xcd, 0x01}, []byte("one")},
+		{append([]byte{0xab, 0xcd, 0x02}, make([]byte, 40)...), sequence(200)},
+		{[]byte{0xab, 0xce}, []byte{}},
+	}
+}
+
+const storageRoot = "b88b491fe743fecc85b4362897c90fd0580437eec1683a96aa1a5b010c11b213"
+
+// The nodes of the proofs
+var proofNodes = []string{
+	"fe2a4468200b45256f9a6cb75a00de90170aa6739e206400000000000000802d06f8876a4a63b6dbdf5bdb5004f317a6993a8df42d66d47d90ba9a7d4837528083824a6de649d293223e2e72253c9a879e10df0f875c1f15a53f58e5c1c8ded680ffdeb1b4c4bde4dbe5f60d98c77089b291edbf8eb9802abf6c8efae0af1a701b80dacea9d87d8cdd7f1802d297a35f55da07e0f861e55c5155201b89d70c255532",
+	"080000000080d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d",
+	"fe410080c0df315429acf1a00023bfc08169dbcfd18036efac5134c718d299d2d75c19ce24060c656e1001000000",
+	"887574683a807d11b8ab53dd9f12f5a4f4c13518532cb24c7385343a14bcbf0d3a1db369ae2d",
+	"fe2a01800c44dc3d2383e8e9f599783a4450bd8cbd51acb7339b166b2ca67e82131fe74480b543bf2efc819a8e575bc09076ad47940f8a20ad7bb44f054c113e6e0c29a1e9501d787472696e7369635f696e646578100000000048116561707061676573200800000000000000",
+	"82a6809028e50794d19e4f538335847fd43b6435ae34afde349f19004bc53a11bb101f",
+	"076f64659101" + hex.EncodeToString(sequence(100)),
+	"ff0100306272616e63682076616c7565807b0949cd350015d663933c8333f7c8b8183f8897e710d7359b42e82c7f7d7f04",
+	"fe00608036283245f6db5df493589433c2e9f5ee67781070ed92e8d4fdda9376e4d9975a080100",
+	"82bc8035aeb5e8a879e7b8d8b45bdc261c7e0ca1485c9f9d7a755b53b899e6a4e55791",
+	"fe060014010c6f6e65807cf6db2f42161d61641956b696db95d60fe0583959f2f28d0b94fc4a3b8f7be3",
+}
+
+func proofOf(nodes ...int) [][]byte {
+	proof := make([][]byte, len(nodes))
+	for i, n := range nodes {
+		proof[i] = unhex(proofNodes[n])
+	}
+	return proof
+}
+
+func TestStorageTrieRoot(t *testing.T) {
+	if root := hex.EncodeToString(TrieRootWithHasher(storagePairs(), blake2)); root != storageRoot {
+		t.Errorf("got %s, expected %s", root, storageRoot)
+	}
+	// The order of the pairs does not matter
+	pairs := storagePairs()
+	for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	}
+	if root := hex.EncodeToString(TrieRootWithHasher(pairs, blake2)); root != storageRoot {
+		t.Errorf("reversed: got %s, expected %s", root, storageRoot)
+	}
+}
+
+func TestVerifyTrieProof(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   []byte
+		proof [][]byte
+		found bool
+		value []byte
+	}{
+		{"leaf", []byte(":auth:\x00\x00\x00\x00"), proofOf(0, 1, 2, 3, 4, 5), true, unhex("d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")},
+		{"long value", []byte(":code"), proofOf(0, 6, 4, 5), true, sequence(100)},
+		{"branch value", []byte{0xab, 0xcd}, proofOf(0, 7, 8, 9), true, []byte("branch value")},
+		{"empty value", []byte{0xab, 0xce}, proofOf(0, 8, 9), true, []byte{}},
+		{"absent leaf", []byte(":auth:\x01\x00\x00\x00"), proofOf(0, 1, 2, 3, 4, 5), false, nil},
+		{"absent child", []byte{0xab, 0xcd, 0x03}, proofOf(0, 10, 7, 8, 9), false, nil},
+		{"absent in extension", []byte{0xab, 0xcf}, proofOf(0, 8, 9), false, nil},
+	}
+	root := unhex(storageRoot)
+	for _, c := range cases {
+		found, value, err := verifyTrieProof(root, c.key, c.proof, blake2)
+		if err != nil || found != c.found || !bytes.Equal(value, c.value) {
+			t.Errorf("%s: got %v, %x, %v", c.name, found, value, err)
+		}
+	}
+}
+
+func TestIncompleteTrieProof(t *testing.T) {
+	root := unhex(storageRoot)
+	key := []byte(":auth:\x00\x00\x00\x00")
+	for _, proof := range [][][]byte{proofOf(1, 2, 3, 4, 5), proofOf(0, 1, 2, 4, 5), proofOf(0, 2, 3, 4, 5), nil} {
+		if _, _, err := verifyTrieProof(root, key, proof, blake2); err == nil || !strings.Contains(err.Error(), "incomplete") {
+			t.Errorf("got %v, expected an incomplete proof", err)
+		}
+	}
+	// The proof of another root
+	other := blake2([]byte("another root"))
+	if _, _, err := verifyTrieProof(other, key, proofOf(0, 1, 2, 3, 4, 5), blake2); err == nil {
+		t.Error("the proof should not verify against another root")
+	}
+}
+
+func TestBadTrieNode(t *testing.T) {
+	// A leaf whose value is longer than the node
+	node := unhex("0300ff10")
+	if _, _, err := verifyTrieProof(blake2(node), []byte{0}, [][]byte{node}, blake2); err != errBadTrieNode {
+		t.Errorf("got %v, expected errBadTrieNode", err)
+	}
+}
@@ -1,6 +1,9 @@
 package srprimitives
 
-import codec "github.com/kyegupov/parity-codec-go/noreflect"
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
 
 type Block struct {
 	Header     Header
@@ -12,6 +15,16 @@ type BlockTypeParamsFactory interface {
 	DecodeExtrinsic(pd codec.Decoder) Extrinsic
 }
 
+func (b *Block) ParityEncode(pe codec.Encoder) {
+	b.Header.ParityEncode(pe)
+	pe.EncodeCollection(len(b.Extrinsics), func(i int) { b.Extrinsics[i].ParityEncode(pe) })
+}
+
+/// The hash of the block, i.e. of its header
+func (b *Block) Hash(hasher Hasher) HashOutput {
+	return b.Header.Hash(hasher)
+}
+
 func (b *Block) ParityDecode(pd codec.Decoder, types BlockTypeParamsFactory) {
 	b.Header.ParityDecode(pd, types)
 	pd.DecodeCollection(
@@ -20,18 +33,50 @@ func (b *Block) ParityDecode(pd codec.Decoder, types BlockTypeParamsFactory) {
 	)
 }
 
+/// Something to identify a block: its hash or its number.
 type BlockId interface {
+	primitives.Enum
 	ImplementsBlockId()
 }
 
-// type HashBlockId struct {
-// 	hash HashOutput
-// }
+const (
+	BlockIdHash   byte = 0
+	BlockIdNumber byte = 1
+)
 
-// func (bid *HashBlockId) ImplementsBlockId() {}
+/// Identify by block header hash.
+type HashBlockId struct {
+	Hash HashOutput
+}
+
+func (_ HashBlockId) ImplementsBlockId() {}
 
-// type NumberBlockId struct {
-// 	number Number
-// }
+func (b HashBlockId) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{BlockIdHash, b.Hash}
+}
 
-// func (bid *NumberBlockId) ImplementsBlockId() {}
+/// Identify by block number.
+type NumberBlockId struct {
+	Number BlockNumber
+}
+
+func (_ NumberBlockId) ImplementsBlockId() {}
+
+func (b NumberBlockId) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{BlockIdNumber, b.Number}
+}
+
+func DecodeBlockId(pd codec.Decoder, types HeaderTypeParamsFactory) BlockId {
+	b := pd.DecodeByte()
+	switch b {
+	case BlockIdHash:
+		h := types.NewHashOutput()
+		h.ParityDecode(pd)
+		return HashBlockId{h}
+	case BlockIdNumber:
+		n := types.BlockNumber(0)
+		n.ParityDecode(pd)
+		return NumberBlockId{n}
+	}
+	panic(primitives.InvalidEnum(b, "BlockId"))
+}
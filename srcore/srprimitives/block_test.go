@@ -0,0 +1,165 @@
+package srprimitives_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Blake2-256 without the host; the tries and the storage roots are not needed here
+type blake2Hasher struct{}
+
+func (_ blake2Hasher) Hash(data []byte) srprimitives.HashOutput {
+	h := primitives.H256(blake2b.Sum256(data))
+	return &h
+}
+
+func (_ blake2Hasher) EnumeratedTrieRoot(values [][]byte) srprimitives.HashOutput {
+	panic("not available natively")
+}
+
+func (_ blake2Hasher) OrderedTrieRoot(values [][]byte) srprimitives.HashOutput {
+	panic("not available natively")
+}
+
+func (_ blake2Hasher) StorageRoot() srprimitives.HashOutput {
+	panic("not available natively")
+}
+
+func (_ blake2Hasher) StorageChangesRoot(parentHash srprimitives.HashOutput, parentNumber uint64) (bool, srprimitives.HashOutput) {
+	panic("not available natively")
+}
+
+// An extrinsic opaque to the runtime, encoded as a byte slice (OpaqueExtrinsic in Rust)
+type opaqueExtrinsic []byte
+
+func (e opaqueExtrinsic) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByteSlice(e)
+}
+
+func (_ opaqueExtrinsic) IsSigned() (bool, bool) { return false, false }
+
+func (_ opaqueExtrinsic) GetFunction() srprimitives.Callable { return nil }
+
+// The types of the node template: H256 hashes and u64 block numbers
+type blockTypes struct{}
+
+func (_ blockTypes) NewHashOutput() srprimitives.HashOutput {
+	return &primitives.H256{}
+}
+
+func (_ blockTypes) BlockNumber(n uint64) srprimitives.BlockNumber {
+	return gohelpers.NewUint64(n)
+}
+
+func (_ blockTypes) DecodeDigestItem(pd codec.Decoder) srprimitives.DigestItem {
+	return srprimitives.DecodeDigestItem(pd, authorityIdFactory)
+}
+
+func (_ blockTypes) DecodeExtrinsic(pd codec.Decoder) srprimitives.Extrinsic {
+	return opaqueExtrinsic(pd.DecodeByteSlice())
+}
+
+func filledHash(b byte) *primitives.H256 {
+	var h primitives.H256
+	for i := range h {
+		h[i] = b
+	}
+	return &h
+}
+
+func testHeader() srprimitives.Header {
+	return srprimitives.Header{
+		filledHash(1),
+		gohelpers.NewUint64(42),
+		filledHash(2),
+		filledHash(3),
+		srprimitives.Digest{[]srprimitives.DigestItem{srprimitives.OtherDigestItem{1, 2}}},
+	}
+}
+
+// The parent hash, the compact number, the state root, the extrinsics root and the digest,
+// as the Header of sr-primitives
+const testHeaderEncoding = "0101010101010101010101010101010101010101010101010101010101010101" + "a8" +
+	"0202020202020202020202020202020202020202020202020202020202020202" +
+	"0303030303030303030303030303030303030303030303030303030303030303" + "0400080102"
+
+func TestHeaderRoundTrip(t *testing.T) {
+	h := testHeader()
+	encoded := codec.ToBytes(&h)
+	if e := hex.EncodeToString(encoded); e != testHeaderEncoding {
+		t.Errorf("encoded as %s", e)
+	}
+	var decoded srprimitives.Header
+	decoded.ParityDecode(codec.Decoder{bytes.NewBuffer(encoded)}, blockTypes{})
+	if !reflect.DeepEqual(decoded, h) {
+		t.Errorf("decoded as %#v", decoded)
+	}
+}
+
+func TestHeaderHash(t *testing.T) {
+	h := testHeader()
+	// Blake2-256 of the encoding above
+	const expected = "5df6b43b89816d0f9dfa54f3b633d991392be2b794715d55af0c8717d52431a8"
+	if hash := hex.EncodeToString(h.Hash(blake2Hasher{}).AsBytes()); hash != expected {
+		t.Errorf("header hash %s, expected %s", hash, expected)
+	}
+	if id := h.HashId(blake2Hasher{}); !reflect.DeepEqual(id, srprimitives.HashBlockId{h.Hash(blake2Hasher{})}) {
+		t.Errorf("hash id %#v", id)
+	}
+	b := srprimitives.Block{h, nil}
+	if !reflect.DeepEqual(b.Hash(blake2Hasher{}), h.Hash(blake2Hasher{})) {
+		t.Error("the block hash is not the header hash")
+	}
+}
+
+func TestBlockRoundTrip(t *testing.T) {
+	b := srprimitives.Block{testHeader(), []srprimitives.Extrinsic{opaqueExtrinsic{7}, opaqueExtrinsic{8, 9}}}
+	encoded := codec.ToBytes(&b)
+	// The header, followed by the extrinsics as a collection of byte slices
+	if e := hex.EncodeToString(encoded); e != testHeaderEncoding+"08"+"0407"+"080809" {
+		t.Errorf("encoded as %s", e)
+	}
+	var decoded srprimitives.Block
+	decoded.ParityDecode(codec.Decoder{bytes.NewBuffer(encoded)}, blockTypes{})
+	if !reflect.DeepEqual(decoded, b) {
+		t.Errorf("decoded as %#v", decoded)
+	}
+}
+
+func TestBlockIdRoundTrip(t *testing.T) {
+	cases := []struct {
+		id       srprimitives.BlockId
+		expected string
+	}{
+		{srprimitives.HashBlockId{filledHash(5)}, "00" + "0505050505050505050505050505050505050505050505050505050505050505"},
+		// The number is not compact-encoded in a BlockId
+		{srprimitives.NumberBlockId{gohelpers.NewUint64(258)}, "01" + "0201000000000000"},
+	}
+	for _, c := range cases {
+		encoded := codec.ToBytes(c.id.EncodeableEnum())
+		if e := hex.EncodeToString(encoded); e != c.expected {
+			t.Errorf("%T encoded as %s, expected %s", c.id, e, c.expected)
+		}
+		decoded := srprimitives.DecodeBlockId(codec.Decoder{bytes.NewBuffer(encoded)}, blockTypes{})
+		if !reflect.DeepEqual(decoded, c.id) {
+			t.Errorf("%T decoded as %#v", c.id, decoded)
+		}
+	}
+}
+
+func TestInvalidBlockId(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	srprimitives.DecodeBlockId(codec.Decoder{bytes.NewBuffer([]byte{2})}, blockTypes{})
+}
@@ -8,6 +8,7 @@ import (
 )
 
 type AuthorityId interface {
+	paritycodec.Encodeable
 	ParityDecode(decoder paritycodec.Decoder)
 }
 
@@ -16,14 +17,7 @@ type Digest struct {
 }
 
 func (d Digest) ParityEncode(pe paritycodec.Encoder) {
-	pe.EncodeCollection(
-		len(d.Logs),
-		func(i int) {
-			pe.EncodeByte(byte(d.Logs[i].DigestItemType()))
-			panic("Digest item encoding not implemented")
-			// TODO: d.Logs[i].ParityEncode(pe)
-		},
-	)
+	pe.EncodeCollection(len(d.Logs), func(i int) { d.Logs[i].ParityEncode(pe) })
 }
 
 func (d *Digest) ParityDecodeDigest(pd paritycodec.Decoder, itemDecoder func(paritycodec.Decoder) DigestItem) {
@@ -42,8 +36,9 @@ const (
 	DtSeal              DigestItemType = 3
 )
 
+/// An item of the digest, encoded as its type followed by its contents
 type DigestItem interface {
-	// paritycodec.Encodeable
+	paritycodec.Encodeable
 	DigestItemType() DigestItemType
 }
 
@@ -89,6 +84,11 @@ func (a *AuthoritiesChange) ParityDecode(pd paritycodec.Decoder, aidFactory func
 	)
 }
 
+func (di AuthoritiesChange) ParityEncode(pe paritycodec.Encoder) {
+	pe.EncodeByte(byte(DtAuthoritiesChange))
+	pe.EncodeCollection(len(di), func(i int) { di[i].ParityEncode(pe) })
+}
+
 func (di AuthoritiesChange) DigestItemType() DigestItemType { return DtAuthoritiesChange }
 
 /// System digest item that contains the root of changes trie at given
@@ -97,6 +97,12 @@ func (di AuthoritiesChange) DigestItemType() DigestItemType { return DtAuthoriti
 /// TODO: *H256
 type ChangesTrieRoot primitives.H256
 
+func (di ChangesTrieRoot) ParityEncode(pe paritycodec.Encoder) {
+	pe.EncodeByte(byte(DtChangesTrieRoot))
+	h := primitives.H256(di)
+	h.ParityEncode(pe)
+}
+
 func (di ChangesTrieRoot) DigestItemType() DigestItemType { return DtChangesTrieRoot }
 
 // end "enum DigestItem"
@@ -105,13 +111,21 @@ type Signature primitives.H512
 
 /// Put a Seal on it
 type Seal struct {
-	number    uint64 // ?????
-	signature Signature
+	/// The number the seal is for, e.g. the slot in Aura
+	Number    uint64
+	Signature Signature
 }
 
 func (s *Seal) ParityDecode(pd paritycodec.Decoder) {
-	s.number = pd.DecodeUint64()
-	(*primitives.H512)(&s.signature).ParityDecode(pd)
+	s.Number = pd.DecodeUint64()
+	(*primitives.H512)(&s.Signature).ParityDecode(pd)
+}
+
+func (di Seal) ParityEncode(pe paritycodec.Encoder) {
+	pe.EncodeByte(byte(DtSeal))
+	pe.EncodeUint64(di.Number)
+	sig := primitives.H512(di.Signature)
+	sig.ParityEncode(pe)
 }
 
 func (di Seal) DigestItemType() DigestItemType { return DtSeal }
@@ -119,4 +133,9 @@ func (di Seal) DigestItemType() DigestItemType { return DtSeal }
 /// Any 'non-system' digest item, opaque to the native code.
 type OtherDigestItem []byte
 
+func (di OtherDigestItem) ParityEncode(pe paritycodec.Encoder) {
+	pe.EncodeByte(byte(DtOther))
+	pe.EncodeByteSlice(di)
+}
+
 func (di OtherDigestItem) DigestItemType() DigestItemType { return DtOther }
@@ -0,0 +1,63 @@
+package srprimitives_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+func authorityIdFactory() srprimitives.AuthorityId {
+	return &primitives.Ed25519AuthorityId{}
+}
+
+func decodeDigestItem(encoded []byte) srprimitives.DigestItem {
+	return srprimitives.DecodeDigestItem(codec.Decoder{bytes.NewBuffer(encoded)}, authorityIdFactory)
+}
+
+func TestDigestItemRoundTrip(t *testing.T) {
+	var root primitives.H256
+	var sig primitives.H512
+	for i := range root {
+		root[i] = 7
+	}
+	for i := range sig {
+		sig[i] = byte(i)
+	}
+	cases := []struct {
+		item     srprimitives.DigestItem
+		expected string // the type byte and the start of the contents
+	}{
+		{srprimitives.OtherDigestItem{1, 2, 3}, "000c010203"},
+		{srprimitives.OtherDigestItem{}, "0000"},
+		{srprimitives.ChangesTrieRoot(root), "020707070707070707070707070707070707070707070707070707070707070707"},
+		{srprimitives.Seal{0x0102030405060708, srprimitives.Signature(sig)}, "030807060504030201000102"},
+		{srprimitives.AuthoritiesChange{&primitives.Ed25519AuthorityId{1}, &primitives.Ed25519AuthorityId{2}}, "0108010000"},
+	}
+	for _, c := range cases {
+		encoded := codec.ToBytes(c.item)
+		if e := hex.EncodeToString(encoded); e[:len(c.expected)] != c.expected {
+			t.Errorf("%T encoded as %s, expected %s...", c.item, e, c.expected)
+		}
+		decoded := decodeDigestItem(encoded)
+		if decoded.DigestItemType() != c.item.DigestItemType() || !reflect.DeepEqual(decoded, c.item) {
+			t.Errorf("%T decoded as %#v", c.item, decoded)
+		}
+		if !bytes.Equal(codec.ToBytes(decoded), encoded) {
+			t.Errorf("%T re-encoded differently", c.item)
+		}
+	}
+}
+
+func TestUnknownDigestItem(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	decodeDigestItem([]byte{4, 0})
+}
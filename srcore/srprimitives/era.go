@@ -0,0 +1,112 @@
+package srprimitives
+
+import (
+	"math"
+	"math/bits"
+
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+/// An era to describe the longevity of a transaction.
+///
+/// Transactions with an immortal era are valid forever, while the mortal ones are only
+/// valid within the block period they were created for.
+type Era interface {
+	codec.Encodeable
+	IsImmortal() bool
+	/// Get the block number of the start of the era whose properties this object
+	/// describes that `current` belongs to.
+	Birth(current uint64) uint64
+	/// Get the block number of the first block at which the era has ended.
+	Death(current uint64) uint64
+}
+
+/// The transaction is valid forever. The genesis hash must be present in the signed content.
+type ImmortalEra struct{}
+
+/// Period and phase are encoded:
+/// - The period of validity from the block hash found in the signing material.
+/// - The phase in the period that this transaction's lifetime begins (and, importantly,
+/// implies which block hash is included in the signature material). If the `period` is
+/// greater than 1 << 12, then it will be a factor of the times greater than 1<<12 that
+/// `period` is.
+type MortalEra struct {
+	Period uint64
+	Phase  uint64
+}
+
+/// Create a new era based on a period (which should be a power of two between 4 and 65536 inclusive)
+/// and a block number on which it should start (or, for long periods, be shortly after the start).
+func NewMortalEra(period uint64, current uint64) MortalEra {
+	// next power of two (1 for 0, as in Rust), in the range [4, 65536]
+	if period > 1<<16 {
+		period = 1 << 16
+	} else if bits.OnesCount64(period) != 1 {
+		period = 1 << uint(64-bits.LeadingZeros64(period))
+	}
+	if period < 4 {
+		period = 4
+	}
+	phase := current % period
+	quantizeFactor := maxUint64(period>>12, 1)
+	quantizedPhase := phase / quantizeFactor * quantizeFactor
+
+	return MortalEra{period, quantizedPhase}
+}
+
+func (_ ImmortalEra) IsImmortal() bool { return true }
+
+func (_ ImmortalEra) Birth(_ uint64) uint64 { return 0 }
+
+func (_ ImmortalEra) Death(_ uint64) uint64 { return math.MaxUint64 }
+
+func (_ ImmortalEra) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(0)
+}
+
+func (_ MortalEra) IsImmortal() bool { return false }
+
+func (e MortalEra) Birth(current uint64) uint64 {
+	return (maxUint64(current, e.Phase)-e.Phase)/e.Period*e.Period + e.Phase
+}
+
+func (e MortalEra) Death(current uint64) uint64 {
+	return e.Birth(current) + e.Period
+}
+
+func (e MortalEra) ParityEncode(pe codec.Encoder) {
+	quantizeFactor := maxUint64(e.Period>>12, 1)
+	trailingZeros := uint64(bits.TrailingZeros64(e.Period))
+	encoded := uint16(minUint64(maxUint64(trailingZeros-1, 1), 15)) | uint16((e.Phase/quantizeFactor)<<4)
+	pe.EncodeByte(byte(encoded))
+	pe.EncodeByte(byte(encoded >> 8))
+}
+
+func DecodeEra(pd codec.Decoder) Era {
+	first := pd.DecodeByte()
+	if first == 0 {
+		return ImmortalEra{}
+	}
+	encoded := uint64(first) + uint64(pd.DecodeByte())<<8
+	period := uint64(2) << (encoded % (1 << 4))
+	quantizeFactor := maxUint64(period>>12, 1)
+	phase := (encoded >> 4) * quantizeFactor
+	if period >= 4 && phase < period {
+		return MortalEra{period, phase}
+	}
+	panic("Invalid period and phase")
+}
+
+func maxUint64(a uint64, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minUint64(a uint64, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
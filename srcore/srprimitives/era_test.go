@@ -0,0 +1,15 @@
+package srprimitives
+
+import "testing"
+
+func TestMortalEraPeriod(t *testing.T) {
+	// As Era::mortal, the next power of two in [4, 65536], 0 becoming 4
+	cases := []struct{ period, expected uint64 }{
+		{0, 4}, {1, 4}, {5, 8}, {64, 64}, {65537, 1 << 16}, {1<<63 + 1, 1 << 16},
+	}
+	for _, c := range cases {
+		if e := NewMortalEra(c.period, 0); e.Period != c.expected {
+			t.Errorf("period %d: got %d, expected %d", c.period, e.Period, c.expected)
+		}
+	}
+}
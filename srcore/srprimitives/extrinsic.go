@@ -1,13 +1,11 @@
 package srprimitives
 
 import (
-	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
-	"github.com/Joystream/tinygo-wasm-substrate/srml/indices"
 	codec "github.com/kyegupov/parity-codec-go/noreflect"
 )
 
 type Extrinsic interface {
-	primitives.Enum
+	codec.Encodeable
 	IsSigned() (bool, bool)
 
 	GetFunction() Callable // All UncheckedExtrinsic
@@ -15,22 +13,39 @@ type Extrinsic interface {
 
 /// Index of a transaction in the chain.
 type Index interface {
+	Number
+}
+
+/// The address format for describing accounts, see indices.Address
+type Address interface {
 	codec.Encodeable
-	codec.Decodeable
-	PlusOne() Index
-	Plus(i int) Index
-	LessThan(o Index) bool
-	GreaterThan(o Index) bool
+	ImplementsAddress()
 }
 
+/// Since implementations of type parameters might differ from runtime to runtime,
+/// the factory is needed to decode extrinsics.
+type ExtrinsicTypeParamsFactory interface {
+	DecodeAddress(pd codec.Decoder) Address
+	/// Decode a signature of the runtime's signature scheme
+	DecodeSignature(pd codec.Decoder) Verify
+	Index(uint64) Index
+	/// Decode the outer "Call" enum, see runtime.Runtime
+	DecodeCall(pd codec.Decoder) Callable
+}
+
+/// Current version of the extrinsic format.
+const TransactionVersion byte = 1
+
+/// Bit of the version byte that is set for signed extrinsics.
+const signedFlag byte = 0x80
+
 type SignatureContent struct {
-	signed    indices.Address
-	signature Signature
-	index     Index
+	Signed    Address
+	Signature Verify
+	Index     Index
 }
 
 // Default implementation
-// TODO: also mortal ones
 type UncheckedExtrinsic struct {
 	/// The signature, address and number of extrinsics have come before from
 	/// the same signer, if this is a signed extrinsic.
@@ -41,19 +56,181 @@ type UncheckedExtrinsic struct {
 }
 
 func (e *UncheckedExtrinsic) IsSigned() (bool, bool) {
-	// TODO: proper impl
-	return e.HasSignature, e.Signature.signed != nil
+	return true, e.HasSignature
+}
+
+func (e *UncheckedExtrinsic) ParityEncode(pe codec.Encoder) {
+	encodeVersioned(pe, e.HasSignature, func(pe codec.Encoder) {
+		e.Signature.Signed.ParityEncode(pe)
+		e.Signature.Signature.ParityEncode(pe)
+		e.Signature.Index.ParityEncode(pe)
+	}, e.Function)
 }
 
-// TODO: encoding
-func (e *UncheckedExtrinsic) EncodeableEnum() primitives.EncodeableEnum {
-	return primitives.EncodeableEnum{}
+func DecodeUncheckedExtrinsic(pd codec.Decoder, types ExtrinsicTypeParamsFactory) *UncheckedExtrinsic {
+	e := UncheckedExtrinsic{}
+	e.HasSignature = decodeVersion(pd)
+	if e.HasSignature {
+		e.Signature.Signed = types.DecodeAddress(pd)
+		e.Signature.Signature = types.DecodeSignature(pd)
+		e.Signature.Index = types.Index(0)
+		e.Signature.Index.ParityDecode(pd)
+	}
+	e.Function = types.DecodeCall(pd)
+	return &e
 }
 
 func (e *UncheckedExtrinsic) GetFunction() Callable {
 	return e.Function
 }
 
+/// Check the signature: the context must implement Lookup to resolve the signer's address.
+/// The signed payload is (index, function).
+func (e *UncheckedExtrinsic) Check(context interface{}) (CheckedExtrinsic, error) {
+	if !e.HasSignature {
+		return CheckedExtrinsic{Function: e.Function}, nil
+	}
+	ok, signed := context.(Lookup).Lookup(e.Signature.Signed)
+	if !ok {
+		return CheckedExtrinsic{}, CheckErrorInvalidAccountIndex
+	}
+	payload := codec.ToBytesCustom(func(pe codec.Encoder) {
+		e.Signature.Index.ParityEncode(pe)
+		e.Function.EncodeableEnum().ParityEncode(pe)
+	})
+	if !VerifyEncoded(e.Signature.Signature, payload, signed) {
+		return CheckedExtrinsic{}, CheckErrorBadSignature
+	}
+	return CheckedExtrinsic{signed, e.Signature.Index, e.Function}, nil
+}
+
+// The binary format of extrinsics must be compatible with substrate's generic `Vec<u8>` type,
+// i.e. be prefixed by the length, followed by the version byte, the signature if any and the call.
+func encodeVersioned(pe codec.Encoder, signed bool, encodeSignature func(codec.Encoder), function Callable) {
+	pe.EncodeByteSlice(codec.ToBytesCustom(func(pe codec.Encoder) {
+		if signed {
+			pe.EncodeByte(TransactionVersion | signedFlag)
+			encodeSignature(pe)
+		} else {
+			pe.EncodeByte(TransactionVersion)
+		}
+		function.EncodeableEnum().ParityEncode(pe)
+	}))
+}
+
+// Reads the length prefix and the version byte; returns whether the extrinsic is signed.
+func decodeVersion(pd codec.Decoder) bool {
+	// We don't need the length prefix
+	pd.DecodeUintCompact()
+
+	version := pd.DecodeByte()
+	if version&^signedFlag != TransactionVersion {
+		panic("Invalid transaction version")
+	}
+	return version&signedFlag != 0
+}
+
+/// Extrinsic that is only valid within its era.
+type MortalExtrinsic interface {
+	Extrinsic
+	Era() Era
+}
+
+type MortalSignatureContent struct {
+	Signed    Address
+	Signature Verify
+	Index     Index // compact-encoded
+	Era       Era
+}
+
+/// A extrinsic right from the external world. This is unchecked and so
+/// can contain a signature. The signed payload includes a checkpoint block hash
+/// (the one at the birth of the era), so the transaction expires after the era ends.
+type UncheckedMortalCompactExtrinsic struct {
+	/// The signature, address, number of extrinsics have come before from
+	/// the same signer and an era describing the longevity of this transaction,
+	/// if this is a signed extrinsic.
+	HasSignature bool
+	Signature    MortalSignatureContent
+	/// The function that should be called.
+	Function Callable
+}
+
+func (e *UncheckedMortalCompactExtrinsic) IsSigned() (bool, bool) {
+	return true, e.HasSignature
+}
+
+func (e *UncheckedMortalCompactExtrinsic) ParityEncode(pe codec.Encoder) {
+	encodeVersioned(pe, e.HasSignature, func(pe codec.Encoder) {
+		e.Signature.Signed.ParityEncode(pe)
+		e.Signature.Signature.ParityEncode(pe)
+		e.Signature.Index.ParityEncodeCompact(pe)
+		e.Signature.Era.ParityEncode(pe)
+	}, e.Function)
+}
+
+func DecodeUncheckedMortalCompactExtrinsic(pd codec.Decoder, types ExtrinsicTypeParamsFactory) *UncheckedMortalCompactExtrinsic {
+	e := UncheckedMortalCompactExtrinsic{}
+	e.HasSignature = decodeVersion(pd)
+	if e.HasSignature {
+		e.Signature.Signed = types.DecodeAddress(pd)
+		e.Signature.Signature = types.DecodeSignature(pd)
+		e.Signature.Index = types.Index(0)
+		e.Signature.Index.ParityDecodeCompact(pd)
+		e.Signature.Era = DecodeEra(pd)
+	}
+	e.Function = types.DecodeCall(pd)
+	return &e
+}
+
+func (e *UncheckedMortalCompactExtrinsic) GetFunction() Callable {
+	return e.Function
+}
+
+func (e *UncheckedMortalCompactExtrinsic) Era() Era {
+	if e.HasSignature {
+		return e.Signature.Era
+	}
+	return ImmortalEra{}
+}
+
+/// Build the payload to be signed: (index, function, era, checkpoint hash), where the checkpoint
+/// is the hash of the block the era was born in. The context must provide CurrentHeight
+/// and BlockNumberToHash.
+func (e *UncheckedMortalCompactExtrinsic) SigningPayload(context interface{}) ([]byte, error) {
+	current := context.(CurrentHeight).CurrentHeight().AsUint64()
+	has, h := context.(BlockNumberToHash).BlockNumberToHash(e.Signature.Era.Birth(current))
+	if !has {
+		return nil, CheckErrorAncientBirthBlock
+	}
+	return codec.ToBytesCustom(func(pe codec.Encoder) {
+		e.Signature.Index.ParityEncodeCompact(pe)
+		e.Function.EncodeableEnum().ParityEncode(pe)
+		e.Signature.Era.ParityEncode(pe)
+		h.ParityEncode(pe)
+	}), nil
+}
+
+/// Check the signature: the context must implement Lookup, CurrentHeight and BlockNumberToHash.
+/// The signed payload is (index, function, era, checkpoint hash), see SigningPayload.
+func (e *UncheckedMortalCompactExtrinsic) Check(context interface{}) (CheckedExtrinsic, error) {
+	if !e.HasSignature {
+		return CheckedExtrinsic{Function: e.Function}, nil
+	}
+	payload, err := e.SigningPayload(context)
+	if err != nil {
+		return CheckedExtrinsic{}, err
+	}
+	ok, signed := context.(Lookup).Lookup(e.Signature.Signed)
+	if !ok {
+		return CheckedExtrinsic{}, CheckErrorInvalidAccountIndex
+	}
+	if !VerifyEncoded(e.Signature.Signature, payload, signed) {
+		return CheckedExtrinsic{}, CheckErrorBadSignature
+	}
+	return CheckedExtrinsic{signed, e.Signature.Index, e.Function}, nil
+}
+
 type CheckedExtrinsic struct {
 	SignatureAccountID AccountId // nil if unsigned
 	SignatureIndex     Index     // nil if unsigned
@@ -71,3 +248,9 @@ func (c *CheckedExtrinsic) Sender() AccountId {
 func (c *CheckedExtrinsic) Deconstruct() (Callable, AccountId) {
 	return c.Function, c.SignatureAccountID
 }
+
+// Implements Weighable
+
+func (c *CheckedExtrinsic) Weight(encodedLen uintptr) Weight {
+	return WeightOf(c.Function, encodedLen)
+}
@@ -0,0 +1,80 @@
+package srprimitives
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srio"
+)
+
+/// Abstraction around hashing, the "Hashing" type parameter of the runtime
+/// (Hash trait in Rust).
+type Hasher interface {
+	/// Produce the hash of some byte-slice.
+	Hash(data []byte) HashOutput
+	/// Produce the trie-db root of a mapping from indices to byte slices.
+	EnumeratedTrieRoot(values [][]byte) HashOutput
+	/// Produce the trie-db root of a sequence of byte slices, keyed by their
+	/// (compact-encoded) indices, i.e. the same as EnumeratedTrieRoot.
+	OrderedTrieRoot(values [][]byte) HashOutput
+	/// Acquire the global storage root.
+	StorageRoot() HashOutput
+	/// Acquire the global storage changes root.
+	StorageChangesRoot(parentHash HashOutput, parentNumber uint64) (bool, HashOutput)
+}
+
+/// Blake2-256 Hash implementation.
+type BlakeTwo256 struct{}
+
+func (_ BlakeTwo256) Hash(data []byte) HashOutput {
+	var h primitives.H256
+	copy(h[:], srio.Blake256(data))
+	return &h
+}
+
+func (_ BlakeTwo256) EnumeratedTrieRoot(values [][]byte) HashOutput {
+	h := primitives.H256(srio.EnumeratedTrieRootBlake256ForByteSlices(values))
+	return &h
+}
+
+func (_ BlakeTwo256) OrderedTrieRoot(values [][]byte) HashOutput {
+	h := primitives.H256(srio.OrderedTrieRoot(values))
+	return &h
+}
+
+func (_ BlakeTwo256) StorageRoot() HashOutput {
+	return srio.StorageRoot()
+}
+
+func (_ BlakeTwo256) StorageChangesRoot(parentHash HashOutput, parentNumber uint64) (bool, HashOutput) {
+	ok, root := srio.StorageChangesRoot(parentHash.AsBytes(), parentNumber)
+	return ok, root
+}
+
+/// Keccak-256 Hash implementation.
+/// The host computes the tries with Blake2 only: the trie roots of values are computed
+/// in the runtime, while the storage roots are the host's (Blake2) ones, which the client checks.
+type Keccak256 struct{}
+
+func (_ Keccak256) Hash(data []byte) HashOutput {
+	var h primitives.H256
+	copy(h[:], srio.Keccak256(data))
+	return &h
+}
+
+func (k Keccak256) EnumeratedTrieRoot(values [][]byte) HashOutput {
+	return k.OrderedTrieRoot(values)
+}
+
+func (_ Keccak256) OrderedTrieRoot(values [][]byte) HashOutput {
+	var h primitives.H256
+	copy(h[:], srio.OrderedTrieRootWithHasher(values, srio.Keccak256))
+	return &h
+}
+
+func (_ Keccak256) StorageRoot() HashOutput {
+	return srio.StorageRoot()
+}
+
+func (_ Keccak256) StorageChangesRoot(parentHash HashOutput, parentNumber uint64) (bool, HashOutput) {
+	ok, root := srio.StorageChangesRoot(parentHash.AsBytes(), parentNumber)
+	return ok, root
+}
@@ -4,14 +4,9 @@ import (
 	codec "github.com/kyegupov/parity-codec-go/noreflect"
 )
 
+/// The number of a block, compact-encoded in the header
 type BlockNumber interface {
-	codec.Encodeable
-	codec.Decodeable
-	// TODO: support 128+ bits
-	AsUint64() uint64
-	MinusOne() BlockNumber
-	NonZero() bool
-	GreaterThan(BlockNumber) bool
+	Number
 }
 
 // TODO: unify with HashOutput
@@ -41,16 +36,32 @@ type Header struct {
 
 func (h *Header) ParityEncode(pe codec.Encoder) {
 	h.ParentHash.ParityEncode(pe)
-	pe.EncodeUintCompact(h.Number.AsUint64())
+	h.Number.ParityEncodeCompact(pe)
 	h.StateRoot.ParityEncode(pe)
 	h.ExtrinsicsRoot.ParityEncode(pe)
 	h.Digest.ParityEncode(pe)
 }
 
+/// The hash of the header: the runtime's Hasher applied to its encoding
+func (h *Header) Hash(hasher Hasher) HashOutput {
+	return hasher.Hash(codec.ToBytes(h))
+}
+
+/// Identifies the block of the header by its hash
+func (h *Header) HashId(hasher Hasher) BlockId {
+	return HashBlockId{h.Hash(hasher)}
+}
+
+/// Identifies the block of the header by its number
+func (h *Header) NumberId() BlockId {
+	return NumberBlockId{h.Number}
+}
+
 func (h *Header) ParityDecode(pd codec.Decoder, types HeaderTypeParamsFactory) {
 	h.ParentHash = types.NewHashOutput()
 	h.ParentHash.ParityDecode(pd)
-	h.Number = types.BlockNumber(pd.DecodeUintCompact())
+	h.Number = types.BlockNumber(0)
+	h.Number.ParityDecodeCompact(pd)
 	h.StateRoot = types.NewHashOutput()
 	h.StateRoot.ParityDecode(pd)
 	h.ExtrinsicsRoot = types.NewHashOutput()
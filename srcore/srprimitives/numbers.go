@@ -0,0 +1,50 @@
+package srprimitives
+
+import (
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+/// An unsigned integer of a width chosen by the runtime, up to 128 bits (SimpleArithmetic
+/// in Rust). See gohelpers.Uint.
+///
+/// The operations accept numbers of any implementation, comparing their values,
+/// and return a number of the receiver's implementation. Overflows are reported
+/// by the checked operations, and clamped by the others.
+type Number interface {
+	codec.Encodeable
+	codec.Decodeable
+	ParityEncodeCompact(pe codec.Encoder)
+	ParityDecodeCompact(pd codec.Decoder)
+
+	/// The value, saturating at the maximum u64
+	AsUint64() uint64
+	/// The value as its least and most significant 64 bits
+	AsUint128() (uint64, uint64)
+	/// A number of the same implementation, saturating at its maximum
+	FromUint64(uint64) Number
+	/// A number of the same implementation, from its least and most significant 64 bits,
+	/// saturating at its maximum
+	FromUint128(lo uint64, hi uint64) Number
+	Zero() Number
+	One() Number
+
+	IsZero() bool
+	NonZero() bool
+	/// Returns -1, 0 or 1 if the number is less than, equal to or greater than the other
+	Cmp(Number) int
+	Equal(Number) bool
+	LessThan(Number) bool
+	GreaterThan(Number) bool
+
+	CheckedAdd(Number) (bool, Number)
+	CheckedSub(Number) (bool, Number)
+	CheckedMul(Number) (bool, Number)
+	SaturatingAdd(Number) Number
+	SaturatingSub(Number) Number
+	SaturatingMul(Number) Number
+	/// Saturating addition of a small number
+	Plus(i int) Number
+	PlusOne() Number
+	/// Saturates at zero
+	MinusOne() Number
+}
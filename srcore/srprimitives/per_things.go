@@ -0,0 +1,375 @@
+package srprimitives
+
+import (
+	"math"
+	"math/bits"
+
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// Fixed-point ratios: Permill, Perbill, Perquintill (parts of a whole) and FixedU64
+// (a non-negative number with 9 decimal places, which can be greater than one).
+
+/// How the result of an operation on ratios is rounded
+type Rounding byte
+
+const (
+	RoundDown Rounding = iota
+	RoundNearest
+	RoundUp
+)
+
+/// A ratio of parts of a whole, which is made of Accuracy() parts.
+type PerThing interface {
+	Parts() uint64
+	Accuracy() uint64
+}
+
+// x * n / d computed with a 128-bit intermediate, saturating at the maximum u64
+func mulDiv(x uint64, n uint64, d uint64, r Rounding) uint64 {
+	hi, lo := bits.Mul64(x, n)
+	if hi >= d {
+		return math.MaxUint64
+	}
+	q, rem := bits.Div64(hi, lo, d)
+	roundUp := (r == RoundUp && rem > 0) || (r == RoundNearest && rem >= d-rem)
+	if roundUp {
+		if q == math.MaxUint64 {
+			return q
+		}
+		q++
+	}
+	return q
+}
+
+/// Multiplies x by the ratio. The result is at most x for the ratios up to one; it saturates
+/// at the maximum u64 for the ratios above one, e.g. FixedU64 or decoded parts above the accuracy.
+func MulRatio(x uint64, p PerThing, r Rounding) uint64 {
+	return mulDiv(x, p.Parts(), p.Accuracy(), r)
+}
+
+/// Multiplies a number of any width by the ratio, the result having the width of n.
+/// The result is computed exactly (with a 192-bit intermediate), and saturates
+/// at the maximum of the width if the ratio is greater than one (e.g. FixedU64).
+func MulRatioNumber(n Number, p PerThing, r Rounding) Number {
+	lo, hi := n.AsUint128()
+	parts, d := p.Parts(), p.Accuracy()
+	// (hi * 2^64 + lo) * parts = top * 2^128 + mid * 2^64 + low
+	hh, hl := bits.Mul64(hi, parts)
+	lh, low := bits.Mul64(lo, parts)
+	mid, carry := bits.Add64(hl, lh, 0)
+	top := hh + carry
+	q2, rem := bits.Div64(0, top, d)
+	q1, rem := bits.Div64(rem, mid, d)
+	q0, rem := bits.Div64(rem, low, d)
+	if (r == RoundUp && rem > 0) || (r == RoundNearest && rem >= d-rem) {
+		q0, carry = bits.Add64(q0, 1, 0)
+		q1, carry = bits.Add64(q1, 0, carry)
+		q2 += carry
+	}
+	if q2 != 0 {
+		return n.FromUint128(math.MaxUint64, math.MaxUint64)
+	}
+	return n.FromUint128(q0, q1)
+}
+
+// The parts of a whole of the given accuracy, approximating p/q (saturating at one)
+func partsFromRational(p uint64, q uint64, accuracy uint64, r Rounding) uint64 {
+	if q == 0 || p >= q {
+		return accuracy
+	}
+	return mulDiv(p, accuracy, q, r)
+}
+
+func saturatingPartsAdd(a uint64, b uint64, accuracy uint64) uint64 {
+	if b > accuracy-a {
+		return accuracy
+	}
+	return a + b
+}
+
+func saturatingPartsSub(a uint64, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
+/// Permill is parts-per-million.
+type Permill uint32
+
+const permillAccuracy = 1000000
+
+/// Converts from parts of a million, saturating at one.
+func PermillFromParts(parts uint32) Permill {
+	if parts > permillAccuracy {
+		return Permill(permillAccuracy)
+	}
+	return Permill(parts)
+}
+
+/// Converts from a percent, saturating at one.
+func PermillFromPercent(x uint32) Permill {
+	if x > 100 {
+		x = 100
+	}
+	return Permill(x * (permillAccuracy / 100))
+}
+
+/// Approximates p/q, saturating at one (also if q is zero).
+func PermillFromRational(p uint64, q uint64, r Rounding) Permill {
+	return Permill(partsFromRational(p, q, permillAccuracy, r))
+}
+
+func PermillOne() Permill {
+	return Permill(permillAccuracy)
+}
+
+func (p Permill) Parts() uint64 {
+	return uint64(p)
+}
+
+func (p Permill) Accuracy() uint64 {
+	return permillAccuracy
+}
+
+/// Multiplies an integer by the ratio, rounding down. The result is exact, as in Substrate 2.0
+/// (Substrate 1.0 saturates the intermediate product at the maximum u64).
+func (p Permill) Mul(x uint64) uint64 {
+	return MulRatio(x, p, RoundDown)
+}
+
+/// Multiplies a number of any width by the ratio, rounding down, see Mul.
+/// For gohelpers.U128 and U256, see their MulRatio.
+func (p Permill) MulNumber(n Number) Number {
+	return MulRatioNumber(n, p, RoundDown)
+}
+
+func (p Permill) SaturatingAdd(o Permill) Permill {
+	return Permill(saturatingPartsAdd(uint64(p), uint64(o), permillAccuracy))
+}
+
+func (p Permill) SaturatingSub(o Permill) Permill {
+	return Permill(saturatingPartsSub(uint64(p), uint64(o)))
+}
+
+func (p Permill) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint32(uint32(p))
+}
+
+func (p Permill) ParityEncodeCompact(pe codec.Encoder) {
+	pe.EncodeUintCompact(uint64(p))
+}
+
+func (p *Permill) ParityDecode(pd codec.Decoder) {
+	*p = Permill(pd.DecodeUint32())
+}
+
+/// Perbill is parts-per-billion.
+type Perbill uint32
+
+const perbillAccuracy = 1000000000
+
+/// Converts from parts of a billion, saturating at one.
+func PerbillFromParts(parts uint32) Perbill {
+	if parts > perbillAccuracy {
+		return Perbill(perbillAccuracy)
+	}
+	return Perbill(parts)
+}
+
+/// Converts from a percent, saturating at one.
+func PerbillFromPercent(x uint32) Perbill {
+	if x > 100 {
+		x = 100
+	}
+	return Perbill(x * (perbillAccuracy / 100))
+}
+
+/// Converts from parts of a million, saturating at one.
+func PerbillFromMillionths(x uint32) Perbill {
+	if x > permillAccuracy {
+		x = permillAccuracy
+	}
+	return Perbill(x * (perbillAccuracy / permillAccuracy))
+}
+
+/// Approximates p/q, saturating at one (also if q is zero).
+func PerbillFromRational(p uint64, q uint64, r Rounding) Perbill {
+	return Perbill(partsFromRational(p, q, perbillAccuracy, r))
+}
+
+func PerbillOne() Perbill {
+	return Perbill(perbillAccuracy)
+}
+
+func (p Perbill) Parts() uint64 {
+	return uint64(p)
+}
+
+func (p Perbill) Accuracy() uint64 {
+	return perbillAccuracy
+}
+
+/// Multiplies an integer by the ratio, rounding down. The result is exact, as in Substrate 2.0
+/// (Substrate 1.0 saturates the intermediate product at the maximum u64).
+func (p Perbill) Mul(x uint64) uint64 {
+	return MulRatio(x, p, RoundDown)
+}
+
+/// Multiplies a number of any width by the ratio, rounding down, see Mul.
+/// For gohelpers.U128 and U256, see their MulRatio.
+func (p Perbill) MulNumber(n Number) Number {
+	return MulRatioNumber(n, p, RoundDown)
+}
+
+func (p Perbill) SaturatingAdd(o Perbill) Perbill {
+	return Perbill(saturatingPartsAdd(uint64(p), uint64(o), perbillAccuracy))
+}
+
+func (p Perbill) SaturatingSub(o Perbill) Perbill {
+	return Perbill(saturatingPartsSub(uint64(p), uint64(o)))
+}
+
+func (p Perbill) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint32(uint32(p))
+}
+
+func (p Perbill) ParityEncodeCompact(pe codec.Encoder) {
+	pe.EncodeUintCompact(uint64(p))
+}
+
+func (p *Perbill) ParityDecode(pd codec.Decoder) {
+	*p = Perbill(pd.DecodeUint32())
+}
+
+/// Perquintill is parts-per-quintillion.
+type Perquintill uint64
+
+const perquintillAccuracy = 1000000000000000000
+
+/// Converts from parts of a quintillion, saturating at one.
+func PerquintillFromParts(parts uint64) Perquintill {
+	if parts > perquintillAccuracy {
+		return Perquintill(perquintillAccuracy)
+	}
+	return Perquintill(parts)
+}
+
+/// Converts from a percent, saturating at one.
+func PerquintillFromPercent(x uint64) Perquintill {
+	if x > 100 {
+		x = 100
+	}
+	return Perquintill(x * (perquintillAccuracy / 100))
+}
+
+/// Approximates p/q, saturating at one (also if q is zero).
+func PerquintillFromRational(p uint64, q uint64, r Rounding) Perquintill {
+	return Perquintill(partsFromRational(p, q, perquintillAccuracy, r))
+}
+
+func PerquintillOne() Perquintill {
+	return Perquintill(perquintillAccuracy)
+}
+
+func (p Perquintill) Parts() uint64 {
+	return uint64(p)
+}
+
+func (p Perquintill) Accuracy() uint64 {
+	return perquintillAccuracy
+}
+
+/// Multiplies an integer by the ratio, rounding down. The result is exact, as in Substrate 2.0
+/// (Substrate 1.0 saturates the intermediate product at the maximum u64).
+func (p Perquintill) Mul(x uint64) uint64 {
+	return MulRatio(x, p, RoundDown)
+}
+
+/// Multiplies a number of any width by the ratio, rounding down, see Mul.
+/// For gohelpers.U128 and U256, see their MulRatio.
+func (p Perquintill) MulNumber(n Number) Number {
+	return MulRatioNumber(n, p, RoundDown)
+}
+
+func (p Perquintill) SaturatingAdd(o Perquintill) Perquintill {
+	return Perquintill(saturatingPartsAdd(uint64(p), uint64(o), perquintillAccuracy))
+}
+
+func (p Perquintill) SaturatingSub(o Perquintill) Perquintill {
+	return Perquintill(saturatingPartsSub(uint64(p), uint64(o)))
+}
+
+func (p Perquintill) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint64(uint64(p))
+}
+
+func (p Perquintill) ParityEncodeCompact(pe codec.Encoder) {
+	pe.EncodeUintCompact(uint64(p))
+}
+
+func (p *Perquintill) ParityDecode(pd codec.Decoder) {
+	*p = Perquintill(pd.DecodeUint64())
+}
+
+/// A non-negative fixed-point number with 9 decimal places (the inner value is the number
+/// multiplied by a billion), e.g. a fee multiplier. The operations saturate.
+type FixedU64 uint64
+
+const fixedU64Div = 1000000000
+
+/// Converts from an integer.
+func FixedU64FromNatural(n uint64) FixedU64 {
+	return FixedU64(mulDiv(n, fixedU64Div, 1, RoundDown))
+}
+
+/// Approximates n/d. Saturates if d is zero.
+func FixedU64FromRational(n uint64, d uint64, r Rounding) FixedU64 {
+	if d == 0 {
+		return FixedU64(math.MaxUint64)
+	}
+	return FixedU64(mulDiv(n, fixedU64Div, d, r))
+}
+
+/// Converts a ratio of parts of a whole.
+func FixedU64FromRatio(p PerThing, r Rounding) FixedU64 {
+	return FixedU64(mulDiv(p.Parts(), fixedU64Div, p.Accuracy(), r))
+}
+
+/// The inner value, i.e. the number multiplied by a billion
+func (f FixedU64) Parts() uint64 {
+	return uint64(f)
+}
+
+func (f FixedU64) Accuracy() uint64 {
+	return fixedU64Div
+}
+
+/// Multiplies an integer by the number, saturating at the maximum u64.
+func (f FixedU64) SaturatingMulInt(x uint64, r Rounding) uint64 {
+	return mulDiv(x, uint64(f), fixedU64Div, r)
+}
+
+func (f FixedU64) SaturatingMul(o FixedU64, r Rounding) FixedU64 {
+	return FixedU64(mulDiv(uint64(f), uint64(o), fixedU64Div, r))
+}
+
+func (f FixedU64) SaturatingAdd(o FixedU64) FixedU64 {
+	if uint64(o) > math.MaxUint64-uint64(f) {
+		return FixedU64(math.MaxUint64)
+	}
+	return f + o
+}
+
+func (f FixedU64) SaturatingSub(o FixedU64) FixedU64 {
+	return FixedU64(saturatingPartsSub(uint64(f), uint64(o)))
+}
+
+func (f FixedU64) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint64(uint64(f))
+}
+
+func (f *FixedU64) ParityDecode(pd codec.Decoder) {
+	*f = FixedU64(pd.DecodeUint64())
+}
@@ -0,0 +1,60 @@
+package srprimitives_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+func TestMulIsExact(t *testing.T) {
+	// x * parts exceeds 2^64, the result is still floor(x * parts / accuracy)
+	if r := srprimitives.Permill(999999).Mul(math.MaxUint64); r != 18446725626965477905 {
+		t.Errorf("Permill(999999) * u64 max = %d", r)
+	}
+	if r := srprimitives.Perbill(500000000).Mul(7); r != 3 {
+		t.Errorf("half of 7 = %d", r)
+	}
+}
+
+func TestMulNumber(t *testing.T) {
+	half := srprimitives.Perbill(500000000)
+	r := half.MulNumber(gohelpers.NewUint128(gohelpers.MaxU128())).(*gohelpers.Uint)
+	if r.Value() != (gohelpers.U128{math.MaxUint64, math.MaxUint64 >> 1}) {
+		t.Errorf("half of u128 max = %v", r.Value())
+	}
+	if r := half.MulNumber(gohelpers.NewUint32(7)); r.AsUint64() != 3 {
+		t.Errorf("half of 7 = %d", r.AsUint64())
+	}
+	// A ratio greater than one saturates at the maximum of the width
+	two := srprimitives.FixedU64(2000000000)
+	if r := srprimitives.MulRatioNumber(gohelpers.NewUint32(math.MaxUint32), two, srprimitives.RoundDown); r.AsUint64() != math.MaxUint32 {
+		t.Errorf("2 * u32 max = %d", r.AsUint64())
+	}
+	if r := srprimitives.MulRatioNumber(gohelpers.NewUint32(7), half, srprimitives.RoundUp); r.AsUint64() != 4 {
+		t.Errorf("half of 7 rounded up = %d", r.AsUint64())
+	}
+}
+
+func TestDecodeIsRaw(t *testing.T) {
+	// Values above the accuracy are decoded as they are, as in Rust
+	encoded := codec.ToBytes(srprimitives.Perbill(2000000000))
+	var p srprimitives.Perbill
+	p.ParityDecode(codec.Decoder{bytes.NewBuffer(encoded)})
+	if p != 2000000000 || !bytes.Equal(codec.ToBytes(p), encoded) {
+		t.Errorf("decoded %d", p)
+	}
+}
+
+func TestMulRatioSaturates(t *testing.T) {
+	// Decoded parts above the accuracy, and a FixedU64 above one
+	if r := srprimitives.Perbill(2000000000).Mul(math.MaxUint64 / 2); r != math.MaxUint64-1 {
+		t.Errorf("2 * (u64 max / 2) = %d", r)
+	}
+	if r := srprimitives.MulRatio(math.MaxUint64, srprimitives.FixedU64(2000000000), srprimitives.RoundUp); r != math.MaxUint64 {
+		t.Errorf("2 * u64 max = %d", r)
+	}
+}
@@ -1,14 +1,44 @@
 package srprimitives
 
-import "github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
-import "github.com/Joystream/tinygo-wasm-substrate/srcore/srio"
-import . "github.com/Joystream/tinygo-wasm-substrate/wasmhelpers"
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srio"
+	. "github.com/Joystream/tinygo-wasm-substrate/wasmhelpers"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+/// Means of signature verification.
+type Verify interface {
+	codec.Encodeable
+	/// Verify a signature. Return `true` if signature is valid for the value.
+	Verify(message []byte, signer AccountId) bool
+}
 
 type Ed25519Signature primitives.H512
 
-func (s Ed25519Signature) Verify(message []byte, signer primitives.H256) {
-	errCode := srio.Ext_ed25519_verify(GetOffset(message), GetLen(message), &s[0], &signer[0])
-	if errCode != 0 {
-		panic("Invalid signature")
+/// The signer must be encoded as an Ed25519 public key (32 bytes)
+func (s Ed25519Signature) Verify(message []byte, signer AccountId) bool {
+	pubkey := codec.ToBytes(signer)
+	if len(pubkey) != 32 {
+		return false
+	}
+	errCode := srio.Ext_ed25519_verify(GetOffset(message), GetLen(message), &s[0], &pubkey[0])
+	return errCode == 0
+}
+
+func (s *Ed25519Signature) ParityEncode(pe codec.Encoder) {
+	(*primitives.H512)(s).ParityEncode(pe)
+}
+
+func (s *Ed25519Signature) ParityDecode(pd codec.Decoder) {
+	(*primitives.H512)(s).ParityDecode(pd)
+}
+
+/// Verify the signature of a payload. Payloads longer than 256 bytes are signed
+/// by their blake2-256 hash.
+func VerifyEncoded(signature Verify, payload []byte, signer AccountId) bool {
+	if len(payload) > 256 {
+		return signature.Verify(srio.Blake256(payload), signer)
 	}
+	return signature.Verify(payload, signer)
 }
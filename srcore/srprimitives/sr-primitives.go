@@ -2,10 +2,13 @@ package srprimitives
 
 import (
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
 )
 
+// Encoded as Result<ApplyOutcome, ApplyError>
 type ApplyResult interface {
 	primitives.Result
+	codec.Encodeable
 	ImplementsApplyResult()
 }
 
@@ -14,11 +17,21 @@ type ApplyOutcome byte
 func (_ ApplyOutcome) ImplementsApplyResult() {}
 func (_ ApplyOutcome) IsError() bool          { return false }
 
+func (o ApplyOutcome) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(0) // Result::Ok
+	pe.EncodeByte(byte(o))
+}
+
 type ApplyError byte
 
 func (_ ApplyError) ImplementsApplyResult() {}
 func (_ ApplyError) IsError() bool          { return true }
 
+func (e ApplyError) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(1) // Result::Err
+	pe.EncodeByte(byte(e))
+}
+
 const (
 	/// Successful application (extrinsic reported no issue).
 	ApplyOutcomeSuccess ApplyOutcome = 0
@@ -32,4 +45,6 @@ const (
 	ApplyErrorFuture ApplyError = 2
 	/// Sending account had too low a balance.
 	ApplyErrorCantPay ApplyError = 3
+	/// Block is full, no more extrinsics can be applied.
+	ApplyErrorFullBlock ApplyError = 255
 )
@@ -1,6 +1,8 @@
 package srprimitives
 
 import (
+	"bytes"
+
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
 	codec "github.com/kyegupov/parity-codec-go/noreflect"
 )
@@ -22,27 +24,143 @@ type HashOutput interface {
 	AsBytes() []byte
 }
 
-// TODO: properly implement https://docs.rs/safe-mix/1.0.0/safe_mix/
-func TripletMix(hashes []HashOutput) HashOutput {
-	return hashes[0]
+// Port of https://docs.rs/safe-mix/1.0.0/safe_mix/
+
+/// The maximum depth of the mix tree, i.e. up to 3^17 items are mixed.
+const TripletMixMaxDepth = 17
+
+// The bitwise majority of three equally long byte strings
+func subMix(seeds *[3][]byte) []byte {
+	r := make([]byte, len(seeds[0]))
+	for i := range r {
+		r[i] = (seeds[0][i] & seeds[1][i]) | (seeds[1][i] & seeds[2][i]) | (seeds[0][i] & seeds[2][i])
+	}
+	return r
+}
+
+/// Mixes the byte strings as a tree of bitwise majorities of triplets, as TripletMix for
+/// iterators in safe-mix: the result is the mix of the last complete triplet at the
+/// greatest depth, i.e. of all the items if their number is a power of 3.
+/// Returns nil if there are less than 3 items.
+func TripletMixBytes(seeds [][]byte) []byte {
+	var accum [TripletMixMaxDepth][3][]byte
+	var result []byte
+	for i, v := range seeds {
+		accum[0][i%3] = v
+		indexAtDepth := i
+		for depth := 0; depth < TripletMixMaxDepth; depth++ {
+			if indexAtDepth%3 != 2 {
+				break
+			}
+			indexAtDepth /= 3
+			// end of the threesome at depth.
+			result = subMix(&accum[depth])
+			if depth == TripletMixMaxDepth-1 {
+				// end of the stream
+				break
+			}
+			accum[depth+1][indexAtDepth%3] = result
+		}
+	}
+	return result
 }
 
-// We do not have actual Hash trait or implementation defined here,
-// since the hashing is not to be performed in the module
+/// Mixes the hashes (see TripletMixBytes) into result, a hash of the same type.
+/// With less than 3 hashes, result is returned as it is, as safe-mix returns
+/// the default value then: it should be the default (zero) hash.
+func TripletMix(hashes []HashOutput, result HashOutput) HashOutput {
+	seeds := make([][]byte, len(hashes))
+	for i := range hashes {
+		seeds[i] = hashes[i].AsBytes()
+	}
+	mixed := TripletMixBytes(seeds)
+	if mixed == nil {
+		return result
+	}
+	result.ParityDecode(codec.Decoder{bytes.NewBuffer(mixed)})
+	return result
+}
 
 type Checked interface {
 }
 
 type Checkable interface {
 	/// Check self, given an instance of Context.
+	/// The error returned is a CheckError.
 	Check(context interface{}) (CheckedExtrinsic, error)
 }
 
+/// Errors of checking an extrinsic
+type CheckError byte
+
+const (
+	/// The signature does not match the payload and the signer.
+	CheckErrorBadSignature CheckError = 0
+	/// The account index of the signer is not (yet) known.
+	CheckErrorInvalidAccountIndex CheckError = 1
+	/// The block the transaction era was born in is not known anymore.
+	CheckErrorAncientBirthBlock CheckError = 2
+)
+
+func (e CheckError) Error() string {
+	switch e {
+	case CheckErrorBadSignature:
+		return "bad signature in extrinsic"
+	case CheckErrorInvalidAccountIndex:
+		return "invalid account index"
+	case CheckErrorAncientBirthBlock:
+		return "transaction birth block ancient"
+	}
+	panic(primitives.InvalidEnum(byte(e), "CheckError"))
+}
+
+/// Means of changing one type into another in a manner dependent on the source type.
+/// Used to resolve the address of the signer into an account id, see system.ChainContext.
+type Lookup interface {
+	/// Attempt a lookup, returns false if the address is not known.
+	Lookup(a Address) (bool, AccountId)
+}
+
+/// Lookup of the addresses passed as call arguments, see indices.Module
+type StaticLookup interface {
+	Lookup
+	DecodeAddress(pd codec.Decoder) Address
+}
+
+/// Provides the current block number, see system.ChainContext
+type CurrentHeight interface {
+	CurrentHeight() BlockNumber
+}
+
+/// Provides the hashes of the previous blocks, see system.ChainContext
+type BlockNumberToHash interface {
+	/// Get the hash for a given block number, or false if none is known.
+	BlockNumberToHash(n uint64) (bool, HashOutput)
+}
+
+type OnInitialise interface {
+	/// The block is being initialised. Implement to have something happen.
+	OnInitialise(n BlockNumber)
+}
+
 type OnFinalise interface {
 	/// The block is being finalised. Implement to have something happen.
 	OnFinalise(n BlockNumber)
 }
 
+/// Off-chain computation trait.
+///
+/// Implementing this trait on a module allows you to perform a long-running tasks
+/// that make validators generate extrinsics (either transactions or inherents)
+/// with results of those long-running computations.
+type OffchainWorker interface {
+	/// This function is being called on every block.
+	///
+	/// Implement this and use special `extern`s to generate transactions or inherents.
+	/// Any state alterations are lost and are not persisted.
+	GenerateExtrinsics(n BlockNumber)
+}
+
 type AccountId interface {
 	codec.Encodeable
 }
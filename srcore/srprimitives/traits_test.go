@@ -0,0 +1,63 @@
+package srprimitives
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"golang.org/x/crypto/blake2b"
+)
+
+// The expected mixes were computed with the TripletMix code of safe-mix 1.0.0,
+// run in Rust on the same hashes.
+
+// The hashes of the blocks 0..n-1, as Blake2-256 of the block number byte
+func blockHashes(n int) []HashOutput {
+	hashes := make([]HashOutput, n)
+	for i := range hashes {
+		h := primitives.H256(blake2b.Sum256([]byte{byte(i)}))
+		hashes[i] = &h
+	}
+	return hashes
+}
+
+func TestTripletMixOfThree(t *testing.T) {
+	mixed := TripletMixBytes([][]byte{{0x01}, {0x03}, {0x07}})
+	if len(mixed) != 1 || mixed[0] != 0x03 {
+		t.Errorf("got %x, expected 03", mixed)
+	}
+}
+
+func TestTripletMixOf3Hashes(t *testing.T) {
+	mixed := TripletMix(blockHashes(3), &primitives.H256{})
+	expected := "ab150a2e1c42b1a7f2db4a4f881c0fda73a056ea8786c9c132b6b8ce88131624"
+	if hex.EncodeToString(mixed.AsBytes()) != expected {
+		t.Errorf("got %x, expected %s", mixed.AsBytes(), expected)
+	}
+}
+
+func TestTripletMixOf81Hashes(t *testing.T) {
+	// As system.CalculateRandom mixes the 81 previous block hashes
+	mixed := TripletMix(blockHashes(81), &primitives.H256{})
+	expected := "15df93596b411bac921a5f5b92bac0c851c6c70a1d09c8b474aea0c2b713daf9"
+	if hex.EncodeToString(mixed.AsBytes()) != expected {
+		t.Errorf("got %x, expected %s", mixed.AsBytes(), expected)
+	}
+}
+
+func TestTripletMixIgnoresIncompleteTriplets(t *testing.T) {
+	// The 10th hash does not complete a triplet, the mix is the one of the first 9
+	mixed := TripletMix(blockHashes(10), &primitives.H256{})
+	expected := "ab3f07456d4313ebb1eb6fdec05a5e926386d0ba9fe6c9c17a64a40e821200ec"
+	if hex.EncodeToString(mixed.AsBytes()) != expected {
+		t.Errorf("got %x, expected %s", mixed.AsBytes(), expected)
+	}
+}
+
+func TestTripletMixOfLessThanThree(t *testing.T) {
+	// As safe-mix, the default (zero) hash
+	mixed := TripletMix(blockHashes(2), &primitives.H256{})
+	if *mixed.(*primitives.H256) != (primitives.H256{}) {
+		t.Errorf("got %x, expected the zero hash", mixed.AsBytes())
+	}
+}
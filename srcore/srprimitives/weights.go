@@ -0,0 +1,57 @@
+package srprimitives
+
+import "math"
+
+/// Numeric range of a transaction weight.
+type Weight uint32
+
+/// A call (aka transaction) that can be weighted. Corresponds to `#[weight = x]`
+/// attributes of dispatchable functions in Rust's `decl_module!`.
+///
+/// Module calls implement this to declare their weight, either fixed or depending
+/// on the call arguments. The outer runtime call simply delegates to the module call.
+type Weighable interface {
+	/// Return the weight of this call.
+	/// The `encodedLen` argument is the encoded length of the transaction/call.
+	Weight(encodedLen uintptr) Weight
+}
+
+/// Weight of a transaction made of a base weight and a weight per encoded byte.
+type TransactionWeight struct {
+	/// Basic weight of the transaction, regardless of its size
+	Base Weight
+	/// Weight added per byte of the encoded transaction
+	PerByte Weight
+}
+
+/// Saturates at the maximum weight, so that a large weight can not wrap to a small one.
+func (t TransactionWeight) Weight(encodedLen uintptr) Weight {
+	w := uint64(t.PerByte) * uint64(encodedLen)
+	if encodedLen != 0 && w/uint64(encodedLen) != uint64(t.PerByte) {
+		return math.MaxUint32
+	}
+	w += uint64(t.Base)
+	if w > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return Weight(w)
+}
+
+/// Weight that does not depend on the length of the transaction.
+type FixedWeight Weight
+
+func (f FixedWeight) Weight(_ uintptr) Weight {
+	return Weight(f)
+}
+
+/// Weight of the calls that do not declare one.
+var DefaultTransactionWeight = TransactionWeight{Base: 0, PerByte: 1}
+
+/// Weigh a call, falling back to DefaultTransactionWeight if it does not implement Weighable.
+func WeightOf(c Callable, encodedLen uintptr) Weight {
+	w, ok := c.(Weighable)
+	if ok {
+		return w.Weight(encodedLen)
+	}
+	return DefaultTransactionWeight.Weight(encodedLen)
+}
@@ -0,0 +1,18 @@
+package srprimitives
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTransactionWeightSaturates(t *testing.T) {
+	if w := (TransactionWeight{10, 2}).Weight(5); w != 20 {
+		t.Errorf("got %d, expected 20", w)
+	}
+	if w := (TransactionWeight{0, math.MaxUint32}).Weight(2); w != math.MaxUint32 {
+		t.Errorf("per byte: got %d", w)
+	}
+	if w := (TransactionWeight{math.MaxUint32, 1}).Weight(1); w != math.MaxUint32 {
+		t.Errorf("base: got %d", w)
+	}
+}
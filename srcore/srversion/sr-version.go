@@ -2,13 +2,25 @@ package srversion
 
 import (
 	paritycodec "github.com/kyegupov/parity-codec-go/noreflect"
+	"golang.org/x/crypto/blake2b"
 )
 
 type ApiID [8]byte
 
+/// The identifier of a runtime API and its version
 type ApiVersion struct {
-	id      ApiID
-	version uint32
+	ID      ApiID
+	Version uint32
+}
+
+func (a ApiVersion) ParityEncode(pe paritycodec.Encoder) {
+	pe.Write(a.ID[:])
+	pe.EncodeUint32(a.Version)
+}
+
+func (a *ApiVersion) ParityDecode(pd paritycodec.Decoder) {
+	pd.Read(a.ID[:])
+	a.Version = pd.DecodeUint32()
 }
 
 type RuntimeVersion struct {
@@ -45,12 +57,55 @@ type RuntimeVersion struct {
 	ApiVersions []ApiVersion
 }
 
-// See generate_runtime_api_id in Rust implementation
+// See generate_runtime_api_id in Rust implementation: the 8-byte Blake2b hash of the name
 func GenerateRuntimeApiId(name string) ApiID {
-	// hash := blake2b.Sum512([]byte(name))
-	var res [8]byte
-	// copy(res[:], hash[:8])
-	return ApiID(res)
+	hash, err := blake2b.New(8, nil)
+	if err != nil {
+		panic(err.Error())
+	}
+	hash.Write([]byte(name))
+	var res ApiID
+	copy(res[:], hash.Sum(nil))
+	return res
+}
+
+/// A runtime API, as declared by decl_runtime_apis! in Rust.
+/// Its functions are exported as "<Name>_<function name>".
+type RuntimeApi struct {
+	Name    string
+	Version uint32
+}
+
+func (a RuntimeApi) ApiVersion() ApiVersion {
+	return ApiVersion{GenerateRuntimeApiId(a.Name), a.Version}
+}
+
+/// The runtime APIs known to Substrate, with their current versions.
+var (
+	/// The `Core` api trait that is mandatory for each runtime.
+	CoreApi = RuntimeApi{"Core", 2}
+	/// The `BlockBuilder` api trait that provides required functions for building a block for a runtime.
+	BlockBuilderApi = RuntimeApi{"BlockBuilder", 2}
+	/// The `TaggedTransactionQueue` api trait for interfering with the new transaction queue.
+	TaggedTransactionQueueApi = RuntimeApi{"TaggedTransactionQueue", 1}
+	/// The `Metadata` api trait that returns metadata for the runtime.
+	MetadataApi = RuntimeApi{"Metadata", 1}
+	/// The offchain worker api.
+	OffchainWorkerApi = RuntimeApi{"OffchainWorkerApi", 1}
+	/// API necessary for block authorship with aura.
+	AuraApi = RuntimeApi{"AuraApi", 1}
+)
+
+/// The versions of the APIs implemented by a runtime (RUNTIME_API_VERSIONS generated
+/// by impl_runtime_apis! in Rust), to be advertised by `Core_version`.
+/// Unlike in Rust, the list is kept by hand: nothing checks that it matches the
+/// `//go:export` functions of the runtime, so every function of each API must be exported.
+func ImplementedApis(apis ...RuntimeApi) []ApiVersion {
+	res := make([]ApiVersion, len(apis))
+	for i, a := range apis {
+		res[i] = a.ApiVersion()
+	}
+	return res
 }
 
 func (v RuntimeVersion) ParityEncode(pe paritycodec.Encoder) {
@@ -59,4 +114,45 @@ func (v RuntimeVersion) ParityEncode(pe paritycodec.Encoder) {
 	pe.EncodeUint32(v.AuthoringVersion)
 	pe.EncodeUint32(v.SpecVersion)
 	pe.EncodeUint32(v.ImplVersion)
+	pe.EncodeCollection(len(v.ApiVersions), func(i int) { v.ApiVersions[i].ParityEncode(pe) })
+}
+
+func (v *RuntimeVersion) ParityDecode(pd paritycodec.Decoder) {
+	v.SpecName = pd.DecodeString()
+	v.ImplName = pd.DecodeString()
+	v.AuthoringVersion = pd.DecodeUint32()
+	v.SpecVersion = pd.DecodeUint32()
+	v.ImplVersion = pd.DecodeUint32()
+	pd.DecodeCollection(
+		func(n int) { v.ApiVersions = make([]ApiVersion, n) },
+		func(i int) { v.ApiVersions[i].ParityDecode(pd) },
+	)
+}
+
+/// Check if this version matches other version for calling into runtime.
+func (v *RuntimeVersion) CanCallWith(other *RuntimeVersion) bool {
+	return v.SpecVersion == other.SpecVersion &&
+		v.SpecName == other.SpecName &&
+		v.AuthoringVersion == other.AuthoringVersion
+}
+
+/// Check if this version matches other version for authoring blocks.
+func (v *RuntimeVersion) CanAuthorWith(other *RuntimeVersion) bool {
+	return v.AuthoringVersion == other.AuthoringVersion &&
+		v.SpecName == other.SpecName
+}
+
+/// Check if the given api with the given version is implemented by the runtime.
+func (v *RuntimeVersion) HasApi(id ApiID, version uint32) bool {
+	for _, a := range v.ApiVersions {
+		if a.ID == id && a.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+/// Check if the given api is implemented by the runtime, with the version declared in Go.
+func (v *RuntimeVersion) Implements(api RuntimeApi) bool {
+	return v.HasApi(GenerateRuntimeApiId(api.Name), api.Version)
 }
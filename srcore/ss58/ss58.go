@@ -0,0 +1,153 @@
+package ss58
+
+import (
+	"errors"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"golang.org/x/crypto/blake2b"
+)
+
+// SS58 is the human-readable format of account ids (public keys), see
+// https://github.com/paritytech/substrate/wiki/External-Address-Format-(SS58)
+//
+// The address is the base58 encoding of: the network prefix byte, the public key
+// and the first 2 bytes of the Blake2b-512 hash of "SS58PRE" ++ prefix ++ public key.
+//
+// Works both natively and inside the runtime, e.g. for debug output:
+//
+//     srio.Print(ss58.EncodeAccountId(&who))
+
+/// The prefix of the generic Substrate networks.
+const DefaultPrefix byte = 42
+
+const checksumLength = 2
+
+var checksumPrefix = []byte("SS58PRE")
+
+var (
+	ErrBadBase58   = errors.New("invalid base58 character")
+	ErrBadLength   = errors.New("invalid address length")
+	ErrBadChecksum = errors.New("invalid checksum")
+)
+
+// Encode a public key of the given network
+func Encode(prefix byte, publicKey []byte) string {
+	data := make([]byte, 0, 1+len(publicKey)+checksumLength)
+	data = append(data, prefix)
+	data = append(data, publicKey...)
+	hash := checksum(data)
+	data = append(data, hash[:checksumLength]...)
+	return base58Encode(data)
+}
+
+// Decode an address into its network prefix and public key of `keyLength` bytes
+func Decode(address string, keyLength int) (byte, []byte, error) {
+	data, err := base58Decode(address)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) != 1+keyLength+checksumLength {
+		return 0, nil, ErrBadLength
+	}
+	body := data[:1+keyLength]
+	hash := checksum(body)
+	for i := 0; i < checksumLength; i++ {
+		if data[1+keyLength+i] != hash[i] {
+			return 0, nil, ErrBadChecksum
+		}
+	}
+	return body[0], body[1:], nil
+}
+
+// Format an account id of the generic Substrate network
+func EncodeAccountId(id *primitives.H256) string {
+	return Encode(DefaultPrefix, id[:])
+}
+
+// Parse an address of any network into an account id
+func DecodeAccountId(address string) (primitives.H256, error) {
+	var id primitives.H256
+	_, key, err := Decode(address, len(id))
+	if err != nil {
+		return id, err
+	}
+	copy(id[:], key)
+	return id, nil
+}
+
+func checksum(data []byte) [64]byte {
+	payload := make([]byte, 0, len(checksumPrefix)+len(data))
+	payload = append(payload, checksumPrefix...)
+	payload = append(payload, data...)
+	return blake2b.Sum512(payload)
+}
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Base58 (Bitcoin alphabet), computed with plain byte arithmetic, since math/big is too heavy for TinyGo
+func base58Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	// log(256) / log(58) < 1.37
+	digits := make([]byte, 0, len(data)*137/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	res := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		res[i] = alphabet[0]
+	}
+	for i, d := range digits {
+		res[len(res)-1-i] = alphabet[d]
+	}
+	return string(res)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+	// log(58) / log(256) < 0.74
+	bytes := make([]byte, 0, len(s)*74/100+1)
+	for i := zeros; i < len(s); i++ {
+		carry := indexInAlphabet(s[i])
+		if carry < 0 {
+			return nil, ErrBadBase58
+		}
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+	res := make([]byte, zeros+len(bytes))
+	for i, b := range bytes {
+		res[len(res)-1-i] = b
+	}
+	return res, nil
+}
+
+func indexInAlphabet(c byte) int {
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] == c {
+			return i
+		}
+	}
+	return -1
+}
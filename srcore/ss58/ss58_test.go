@@ -0,0 +1,90 @@
+package ss58
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+)
+
+// The sr25519 public keys of the well-known development accounts
+const (
+	alice = "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"
+	bob   = "8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48"
+)
+
+var vectors = []struct {
+	prefix    byte
+	publicKey string
+	address   string
+}{
+	{DefaultPrefix, alice, "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"},
+	{DefaultPrefix, bob, "5FHneW46xGXgs5mUiveU4sbTyGBzmstUspZC92UhjJM694ty"},
+	// Polkadot and Kusama
+	{0, alice, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5"},
+	{2, alice, "HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F"},
+}
+
+func TestKnownVectors(t *testing.T) {
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.publicKey)
+		if address := Encode(v.prefix, key); address != v.address {
+			t.Errorf("Encode(%d, %s) = %s, expected %s", v.prefix, v.publicKey, address, v.address)
+		}
+		prefix, decoded, err := Decode(v.address, len(key))
+		if err != nil || prefix != v.prefix || hex.EncodeToString(decoded) != v.publicKey {
+			t.Errorf("Decode(%s) = %d, %x, %v", v.address, prefix, decoded, err)
+		}
+	}
+}
+
+func TestAccountIdRoundTrip(t *testing.T) {
+	var id primitives.H256
+	key, _ := hex.DecodeString(alice)
+	copy(id[:], key)
+	address := EncodeAccountId(&id)
+	if address != vectors[0].address {
+		t.Errorf("EncodeAccountId = %s", address)
+	}
+	decoded, err := DecodeAccountId(address)
+	if err != nil || decoded != id {
+		t.Errorf("DecodeAccountId = %x, %v", decoded, err)
+	}
+}
+
+func TestLeadingZeros(t *testing.T) {
+	// Zero bytes are encoded as leading '1's
+	for _, data := range [][]byte{{}, {0}, {0, 0, 1}, {0, 0xff, 0}} {
+		decoded, err := base58Decode(base58Encode(data))
+		if err != nil || hex.EncodeToString(decoded) != hex.EncodeToString(data) {
+			t.Errorf("round trip of %x: %x, %v", data, decoded, err)
+		}
+	}
+	if base58Encode([]byte{0, 0, 1}) != "112" {
+		t.Errorf("base58Encode(000001) = %s", base58Encode([]byte{0, 0, 1}))
+	}
+}
+
+func TestBadChecksum(t *testing.T) {
+	// The last character of Alice's address changed
+	if _, err := DecodeAccountId("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQZ"); err != ErrBadChecksum {
+		t.Errorf("got %v, expected ErrBadChecksum", err)
+	}
+}
+
+func TestBadLength(t *testing.T) {
+	// A 32 byte key is not a 33 byte one, nor is a truncated address
+	if _, _, err := Decode(vectors[0].address, 33); err != ErrBadLength {
+		t.Errorf("got %v, expected ErrBadLength", err)
+	}
+	if _, err := DecodeAccountId(vectors[0].address[:40]); err != ErrBadLength {
+		t.Errorf("got %v, expected ErrBadLength", err)
+	}
+}
+
+func TestBadCharacter(t *testing.T) {
+	// '0', 'O', 'I' and 'l' are not in the alphabet
+	if _, err := DecodeAccountId("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQ0"); err != ErrBadBase58 {
+		t.Errorf("got %v, expected ErrBadBase58", err)
+	}
+}
@@ -0,0 +1,108 @@
+package aura
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/storage"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/timestamp"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// The Aura module extends Aura consensus by managing offline reporting.
+// The authorities take turns authoring blocks, one per slot; the slot is derived
+// from the timestamp of the block.
+
+/// Something which can handle Aura consensus reports.
+type HandleReport interface {
+	HandleReport(report Report)
+}
+
+/// A report of skipped authorities in aura.
+type Report struct {
+	// The first skipped slot.
+	StartSlot uint64
+	// The number of times authorities were skipped.
+	Skipped uint64
+}
+
+/// Call the closure with (validatorIndex, punishmentCount) for each
+/// validator to punish.
+func (r Report) Punish(validatorCount uint64, punishWith func(index uint64, count uint64)) {
+	// If all validators have been skipped, then it implies some sort of
+	// systematic problem or a bug that causes punishment of everyone.
+	if r.Skipped < validatorCount {
+		for i := uint64(0); i < r.Skipped; i++ {
+			punishWith((r.StartSlot+i)%validatorCount, 1)
+		}
+	}
+}
+
+type Module struct {
+	support.BaseModule
+	TypeParamsFactory support.TypeParamsFactory
+	Timestamp         *timestamp.Module
+	/// Handles the skipped slots. Can be nil.
+	HandleReport HandleReport
+
+	/// The last timestamp.
+	LastTimestampStore storage.SimpleStorageValue
+}
+
+func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
+	m.TypeParamsFactory = r
+
+	m.LastTimestampStore = storage.SimpleStorageValue{
+		[]byte("Aura LastTimestamp"),
+		"",
+		func() storage.StoredValue { return timestamp.Moment(0) },
+		func(pd codec.Decoder) storage.StoredValue { return timestamp.Moment(pd.DecodeUint64()) },
+	}
+}
+
+/// The last timestamp.
+func (m *Module) Last() timestamp.Moment {
+	return m.LastTimestampStore.Get().(timestamp.Moment)
+}
+
+/// Determine the Aura slot-duration based on the timestamp module configuration.
+func (m *Module) SlotDuration() uint64 {
+	// we double the minimum block-period so each author can always propose within
+	// the majority of their slot.
+	period := uint64(m.Timestamp.MinimumPeriod())
+	if period > (1<<64-1)/2 {
+		return 1<<64 - 1
+	}
+	return period * 2
+}
+
+// Implements timestamp.OnTimestampSet: checks that at most one block is authored per slot,
+// and reports the skipped slots.
+func (m *Module) OnTimestampSet(now timestamp.Moment) {
+	slotDuration := timestamp.Moment(m.SlotDuration())
+
+	last := m.Last()
+	m.LastTimestampStore.Put(now)
+
+	if last == 0 {
+		return
+	}
+
+	if slotDuration == 0 {
+		panic("Aura slot duration cannot be zero.")
+	}
+
+	lastSlot := last / slotDuration
+	firstSkipped := lastSlot + 1
+	curSlot := now / slotDuration
+
+	if lastSlot >= curSlot {
+		panic("Only one block may be authored per slot.")
+	}
+	if curSlot == firstSkipped {
+		return
+	}
+
+	if m.HandleReport != nil {
+		skippedSlots := curSlot - lastSlot - 1
+		m.HandleReport.HandleReport(Report{uint64(firstSkipped), uint64(skippedSlots)})
+	}
+}
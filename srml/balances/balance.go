@@ -0,0 +1,30 @@
+package balances
+
+import (
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+/// The balance of an account. Runtimes choose the implementation
+/// (e.g. a 64- or 128-bit unsigned integer), see BalanceFactory.
+type Balance interface {
+	codec.Encodeable
+	/// Compact encoding, used for the arguments of the calls
+	ParityEncodeCompact(pe codec.Encoder)
+	IsZero() bool
+	LessThan(other Balance) bool
+	/// Returns false on overflow
+	CheckedAdd(other Balance) (bool, Balance)
+	/// Returns false on underflow
+	CheckedSub(other Balance) (bool, Balance)
+	SaturatingAdd(other Balance) Balance
+	SaturatingSub(other Balance) Balance
+	SaturatingMul(other Balance) Balance
+}
+
+/// Since the balance type differs from runtime to runtime, the runtime's
+/// TypeParamsFactory implements this to instantiate balances.
+type BalanceFactory interface {
+	Balance(uint64) Balance
+	DecodeBalance(pd codec.Decoder) Balance
+	DecodeCompactBalance(pd codec.Decoder) Balance
+}
@@ -0,0 +1,548 @@
+package balances
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/storage"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/system"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// The balances module provides functionality for handling accounts and balances:
+// transfers, fees, and reaping of the accounts whose balance falls below
+// the existential deposit.
+
+/// A handler for when an account's free balance falls to zero (is reaped).
+type OnFreeBalanceZero interface {
+	/// The account's free balance has just dropped to zero, its storage should be cleaned up.
+	OnFreeBalanceZero(who srprimitives.AccountId)
+}
+
+type Module struct {
+	support.BaseModule
+	TypeParamsFactory support.TypeParamsFactory
+	BalanceFactory    BalanceFactory
+	System            *system.Module
+	/// Resolves the addresses passed to the calls, usually indices.Module.
+	Lookup srprimitives.StaticLookup
+	/// A function which is invoked when the free-balance has fallen below the existential deposit and
+	/// has been reduced to zero. Can be nil.
+	OnFreeBalanceZero OnFreeBalanceZero
+	/// Handler for when a new account is created. Can be nil.
+	OnNewAccount system.OnNewAccount
+
+	/// The total amount of stake on the system.
+	TotalIssuanceStore storage.SimpleStorageValue
+	/// The minimum amount allowed to keep an account open.
+	ExistentialDepositStore storage.SimpleStorageValue
+	/// The fee required to make a transfer.
+	TransferFeeStore storage.SimpleStorageValue
+	/// The fee required to create an account.
+	CreationFeeStore storage.SimpleStorageValue
+	/// The fee to be paid for making a transaction; the base.
+	TransactionBaseFeeStore storage.SimpleStorageValue
+	/// The fee to be paid for making a transaction; the per-byte portion.
+	TransactionByteFeeStore storage.SimpleStorageValue
+	/// The 'free' balance of a given account.
+	///
+	/// This is the only balance that matters in terms of most operations on tokens. It is
+	/// alone used to determine the balance when in the contract execution environment. When this
+	/// balance falls below the value of `ExistentialDeposit`, then the 'current account' is
+	/// deleted: specifically `FreeBalance`. Furthermore, `OnFreeBalanceZero` callback
+	/// is invoked, giving a chance to external modules to cleanup data associated with
+	/// the deleted account.
+	///
+	/// `system::AccountNonce` is also deleted if `ReservedBalance` is also zero (it also gets
+	/// collapsed to zero if it ever becomes less than `ExistentialDeposit`.
+	FreeBalanceStore storage.MapStorageValue
+	/// The amount of the balance of a given account that is externally reserved; this can still get
+	/// slashed, but gets slashed last of all.
+	///
+	/// This balance is a 'reserve' balance that other subsystems use in order to set aside tokens
+	/// that are still 'owned' by the account holder, but which are suspendable.
+	///
+	/// When this balance falls below the value of `ExistentialDeposit`, then this 'reserve account'
+	/// is deleted: specifically, `ReservedBalance`.
+	///
+	/// `system::AccountNonce` is also deleted if `FreeBalance` is also zero (it also gets
+	/// collapsed to zero if it ever becomes less than `ExistentialDeposit`.
+	ReservedBalanceStore storage.MapStorageValue
+}
+
+/// The runtime's TypeParamsFactory must also implement BalanceFactory
+func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
+	m.TypeParamsFactory = r
+	m.BalanceFactory = r.(BalanceFactory)
+
+	m.TotalIssuanceStore = m.balanceValue("Balances TotalIssuance")
+	m.ExistentialDepositStore = m.balanceValue("Balances ExistentialDeposit")
+	m.TransferFeeStore = m.balanceValue("Balances TransferFee")
+	m.CreationFeeStore = m.balanceValue("Balances CreationFee")
+	m.TransactionBaseFeeStore = m.balanceValue("Balances TransactionBaseFee")
+	m.TransactionByteFeeStore = m.balanceValue("Balances TransactionByteFee")
+	m.FreeBalanceStore = m.balanceMap("Balances FreeBalance")
+	m.ReservedBalanceStore = m.balanceMap("Balances ReservedBalance")
+}
+
+func (m *Module) balanceValue(key string) storage.SimpleStorageValue {
+	return storage.SimpleStorageValue{
+		[]byte(key),
+		"",
+		func() storage.StoredValue { return m.BalanceFactory.Balance(0) },
+		func(pd codec.Decoder) storage.StoredValue { return m.BalanceFactory.DecodeBalance(pd) },
+	}
+}
+
+func (m *Module) balanceMap(prefix string) storage.MapStorageValue {
+	return storage.MapStorageValue{
+		[]byte(prefix),
+		"",
+		func() storage.StoredValue { return m.BalanceFactory.Balance(0) },
+		func(pd codec.Decoder) storage.StoredValue { return m.BalanceFactory.DecodeBalance(pd) },
+	}
+}
+
+/// The initial balance of an account
+type AccountBalance struct {
+	Who     srprimitives.AccountId
+	Balance Balance
+}
+
+/// Initial state of the module, nil values are not set
+type GenesisConfig struct {
+	Balances           []AccountBalance
+	ExistentialDeposit Balance
+	TransferFee        Balance
+	CreationFee        Balance
+	TransactionBaseFee Balance
+	TransactionByteFee Balance
+}
+
+/// Writes the initial state of the module into the storage.
+func (m *Module) BuildStorage(config GenesisConfig) {
+	m.ExistentialDepositStore.Put(config.ExistentialDeposit)
+	m.TransferFeeStore.Put(config.TransferFee)
+	m.CreationFeeStore.Put(config.CreationFee)
+	m.TransactionBaseFeeStore.Put(config.TransactionBaseFee)
+	m.TransactionByteFeeStore.Put(config.TransactionByteFee)
+
+	totalIssuance := m.BalanceFactory.Balance(0)
+	for _, b := range config.Balances {
+		m.FreeBalanceStore.Insert(b.Who, b.Balance)
+		totalIssuance = totalIssuance.SaturatingAdd(b.Balance)
+	}
+	m.TotalIssuanceStore.Put(totalIssuance)
+}
+
+func (m *Module) TotalIssuance() Balance {
+	return m.TotalIssuanceStore.Get().(Balance)
+}
+
+func (m *Module) ExistentialDeposit() Balance {
+	return m.ExistentialDepositStore.Get().(Balance)
+}
+
+func (m *Module) TransferFee() Balance {
+	return m.TransferFeeStore.Get().(Balance)
+}
+
+func (m *Module) CreationFee() Balance {
+	return m.CreationFeeStore.Get().(Balance)
+}
+
+func (m *Module) TransactionBaseFee() Balance {
+	return m.TransactionBaseFeeStore.Get().(Balance)
+}
+
+func (m *Module) TransactionByteFee() Balance {
+	return m.TransactionByteFeeStore.Get().(Balance)
+}
+
+func (m *Module) FreeBalance(who srprimitives.AccountId) Balance {
+	return m.FreeBalanceStore.Get(who).(Balance)
+}
+
+func (m *Module) ReservedBalance(who srprimitives.AccountId) Balance {
+	return m.ReservedBalanceStore.Get(who).(Balance)
+}
+
+/// The combined balance of `who`.
+func (m *Module) TotalBalance(who srprimitives.AccountId) Balance {
+	return m.FreeBalance(who).SaturatingAdd(m.ReservedBalance(who))
+}
+
+/// Implements system.IsDeadAccount: an account is dead when it has no balance at all.
+func (m *Module) IsDeadAccount(who srprimitives.AccountId) bool {
+	return m.TotalBalance(who).IsZero()
+}
+
+/// Outcome of a balance update.
+type UpdateBalanceOutcome byte
+
+const (
+	/// Account balance was simply updated.
+	UpdateBalanceOutcomeUpdated UpdateBalanceOutcome = 0
+	/// The update has led to killing of the account.
+	UpdateBalanceOutcomeAccountKilled UpdateBalanceOutcome = 1
+)
+
+/// Set the free balance of an account to some new value.
+///
+/// Will enforce ExistentialDeposit law, annulling the account as needed.
+/// In that case it will return `AccountKilled`.
+func (m *Module) SetFreeBalance(who srprimitives.AccountId, balance Balance) UpdateBalanceOutcome {
+	m.FreeBalanceStore.Insert(who, balance)
+	if balance.LessThan(m.ExistentialDeposit()) {
+		m.onFreeTooLow(who)
+		return UpdateBalanceOutcomeAccountKilled
+	}
+	return UpdateBalanceOutcomeUpdated
+}
+
+/// Set the free balance on an account to some new value.
+///
+/// Same as SetFreeBalance, but will create a new account.
+///
+/// Returns `AccountKilled` if the balance is less than ExistentialDeposit
+/// and the account did not exist: nothing is done in that case.
+func (m *Module) SetFreeBalanceCreating(who srprimitives.AccountId, balance Balance) UpdateBalanceOutcome {
+	potentiallyNewAccount := !m.FreeBalanceStore.Exists(who)
+	if potentiallyNewAccount && balance.LessThan(m.ExistentialDeposit()) {
+		// NOTE: This is orphaned dust: it is not accounted for in the total issuance.
+		return UpdateBalanceOutcomeAccountKilled
+	}
+	if potentiallyNewAccount {
+		m.newAccount(who, balance)
+	}
+	return m.SetFreeBalance(who, balance)
+}
+
+/// Set the reserved balance of an account to some new value.
+///
+/// Will enforce ExistentialDeposit law, annulling the account as needed.
+/// In that case it will return `AccountKilled`.
+func (m *Module) SetReservedBalance(who srprimitives.AccountId, balance Balance) UpdateBalanceOutcome {
+	m.ReservedBalanceStore.Insert(who, balance)
+	if balance.LessThan(m.ExistentialDeposit()) {
+		m.onReservedTooLow(who)
+		return UpdateBalanceOutcomeAccountKilled
+	}
+	return UpdateBalanceOutcomeUpdated
+}
+
+/// Adds up to `value` to the free balance of `who`. If `who` doesn't exist, it is created.
+///
+/// This is a sensitive function since it circumvents any fees associated with account
+/// setup. Ensure it is only called by trusted code.
+func (m *Module) IncreaseFreeBalanceCreating(who srprimitives.AccountId, value Balance) UpdateBalanceOutcome {
+	ok, newBalance := m.FreeBalance(who).CheckedAdd(value)
+	if !ok {
+		// defensive only: overflow should never happen, however in case it does, then this
+		// operation is a no-op.
+		return UpdateBalanceOutcomeUpdated
+	}
+	if !m.FreeBalanceStore.Exists(who) && newBalance.LessThan(m.ExistentialDeposit()) {
+		// Nothing is credited (see SetFreeBalanceCreating), the issuance stays the same
+		return UpdateBalanceOutcomeAccountKilled
+	}
+	m.increaseTotalStakeBy(value)
+	return m.SetFreeBalanceCreating(who, newBalance)
+}
+
+/// Transfer some liquid free balance to another account.
+///
+/// `transfer` will set the `FreeBalance` of the sender and receiver.
+/// It will decrease the total issuance of the system by the `TransferFee`.
+/// If the sender's account is below the existential deposit as a result
+/// of the transfer, the account will be reaped.
+func (m *Module) Transfer(transactor srprimitives.AccountId, dest srprimitives.AccountId, value Balance) error {
+	fromBalance := m.FreeBalance(transactor)
+	toBalance := m.FreeBalance(dest)
+	wouldCreate := toBalance.IsZero()
+	fee := m.TransferFee()
+	if wouldCreate {
+		fee = m.CreationFee()
+	}
+	ok, liability := value.CheckedAdd(fee)
+	if !ok {
+		return errors.New("got overflow after adding a fee to value")
+	}
+	ok, newFromBalance := fromBalance.CheckedSub(liability)
+	if !ok {
+		return errors.New("balance too low to send value")
+	}
+	if wouldCreate && value.LessThan(m.ExistentialDeposit()) {
+		return errors.New("value too low to create account")
+	}
+
+	// NOTE: total stake being stored in the same type means that this could never overflow
+	// but better to be safe than sorry.
+	ok, newToBalance := toBalance.CheckedAdd(value)
+	if !ok {
+		return errors.New("destination balance too high to receive value")
+	}
+
+	if !bytes.Equal(codec.ToBytes(transactor), codec.ToBytes(dest)) {
+		m.SetFreeBalance(transactor, newFromBalance)
+		m.decreaseTotalStakeBy(fee)
+		m.SetFreeBalanceCreating(dest, newToBalance)
+		m.System.DepositEvent(m.OuterEvent(EventTransfer{transactor, dest, value, fee}))
+	}
+	return nil
+}
+
+/// Implements srprimitives.MakePayment: charges the transaction fees of `transactor`.
+func (m *Module) MakePayment(transactor srprimitives.AccountId, encodedLen uintptr) error {
+	b := m.FreeBalance(transactor)
+	byteFee := m.TransactionByteFee().SaturatingMul(m.BalanceFactory.Balance(uint64(encodedLen)))
+	transactionFee := m.TransactionBaseFee().SaturatingAdd(byteFee)
+	if b.LessThan(transactionFee.SaturatingAdd(m.ExistentialDeposit())) {
+		return errors.New("not enough funds for transaction fee")
+	}
+	_, newBalance := b.CheckedSub(transactionFee)
+	m.FreeBalanceStore.Insert(transactor, newBalance)
+	m.decreaseTotalStakeBy(transactionFee)
+	return nil
+}
+
+/// Register a new account (with existential balance).
+func (m *Module) newAccount(who srprimitives.AccountId, balance Balance) {
+	if m.OnNewAccount != nil {
+		m.OnNewAccount.OnNewAccount(who)
+	}
+	m.System.DepositEvent(m.OuterEvent(EventNewAccount{who, balance}))
+}
+
+/// Unregister an account.
+///
+/// This just removes the nonce and leaves an event.
+func (m *Module) reapAccount(who srprimitives.AccountId) {
+	m.System.AccountNonceStore.Remove(who)
+	m.System.DepositEvent(m.OuterEvent(EventReapedAccount{who}))
+}
+
+/// Kill an account's free portion.
+func (m *Module) onFreeTooLow(who srprimitives.AccountId) {
+	dust := m.FreeBalanceStore.Take(who).(Balance)
+	m.decreaseTotalStakeBy(dust)
+
+	if m.OnFreeBalanceZero != nil {
+		m.OnFreeBalanceZero.OnFreeBalanceZero(who)
+	}
+
+	if m.ReservedBalance(who).IsZero() {
+		m.reapAccount(who)
+	}
+}
+
+/// Kill an account's reserved portion.
+func (m *Module) onReservedTooLow(who srprimitives.AccountId) {
+	dust := m.ReservedBalanceStore.Take(who).(Balance)
+	m.decreaseTotalStakeBy(dust)
+
+	if m.FreeBalance(who).IsZero() {
+		m.reapAccount(who)
+	}
+}
+
+/// Increase TotalIssuance by Value.
+func (m *Module) increaseTotalStakeBy(value Balance) {
+	m.TotalIssuanceStore.Put(m.TotalIssuance().SaturatingAdd(value))
+}
+
+/// Decrease TotalIssuance by Value.
+func (m *Module) decreaseTotalStakeBy(value Balance) {
+	m.TotalIssuanceStore.Put(m.TotalIssuance().SaturatingSub(value))
+}
+
+// Method IDs
+const (
+	TransferId   byte = 0
+	SetBalanceId byte = 1
+)
+
+/// Transfer some liquid free balance to another staker.
+type TransferCall struct {
+	m     *Module
+	Dest  srprimitives.Address
+	Value Balance
+}
+
+func (m *Module) TransferCall(dest srprimitives.Address, value Balance) TransferCall {
+	return TransferCall{m, dest, value}
+}
+
+func (c TransferCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{TransferId, c}
+}
+
+// Encodes the arguments of the call
+func (c TransferCall) ParityEncode(pe codec.Encoder) {
+	c.Dest.ParityEncode(pe)
+	c.Value.ParityEncodeCompact(pe)
+}
+
+func (c TransferCall) Dispatch(o srprimitives.Origin) error {
+	transactor, err := system.EnsureSigned(o)
+	if err != nil {
+		return err
+	}
+	ok, dest := c.m.Lookup.Lookup(c.Dest)
+	if !ok {
+		return errors.New("invalid account index")
+	}
+	return c.m.Transfer(transactor, dest, c.Value)
+}
+
+/// Set the balances of a given account.
+type SetBalanceCall struct {
+	m        *Module
+	Who      srprimitives.Address
+	Free     Balance
+	Reserved Balance
+}
+
+func (m *Module) SetBalanceCall(who srprimitives.Address, free Balance, reserved Balance) SetBalanceCall {
+	return SetBalanceCall{m, who, free, reserved}
+}
+
+func (c SetBalanceCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{SetBalanceId, c}
+}
+
+// Encodes the arguments of the call
+func (c SetBalanceCall) ParityEncode(pe codec.Encoder) {
+	c.Who.ParityEncode(pe)
+	c.Free.ParityEncodeCompact(pe)
+	c.Reserved.ParityEncodeCompact(pe)
+}
+
+func (c SetBalanceCall) Dispatch(o srprimitives.Origin) error {
+	if err := system.EnsureRoot(o); err != nil {
+		return err
+	}
+	ok, who := c.m.Lookup.Lookup(c.Who)
+	if !ok {
+		return errors.New("invalid account index")
+	}
+	c.m.SetFreeBalance(who, c.Free)
+	c.m.SetReservedBalance(who, c.Reserved)
+	return nil
+}
+
+func (m *Module) DecodeCall(pd codec.Decoder) srprimitives.Callable {
+	b := pd.DecodeByte()
+	switch b {
+	case TransferId:
+		dest := m.Lookup.DecodeAddress(pd)
+		return TransferCall{m, dest, m.BalanceFactory.DecodeCompactBalance(pd)}
+	case SetBalanceId:
+		who := m.Lookup.DecodeAddress(pd)
+		free := m.BalanceFactory.DecodeCompactBalance(pd)
+		return SetBalanceCall{m, who, free, m.BalanceFactory.DecodeCompactBalance(pd)}
+	}
+	panic(primitives.InvalidEnum(b, "balances Call"))
+}
+
+func (m *Module) CallableBelongsToThisModule(c srprimitives.Callable) bool {
+	switch c.(type) {
+	case TransferCall, SetBalanceCall:
+		return true
+	}
+	return false
+}
+
+/// A new account was created.
+type EventNewAccount struct {
+	Who     srprimitives.AccountId
+	Balance Balance
+}
+
+func (e EventNewAccount) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(0)
+	e.Who.ParityEncode(pe)
+	e.Balance.ParityEncode(pe)
+}
+
+/// An account was reaped.
+type EventReapedAccount struct {
+	Who srprimitives.AccountId
+}
+
+func (e EventReapedAccount) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(1)
+	e.Who.ParityEncode(pe)
+}
+
+/// Transfer succeeded (from, to, value, fees).
+type EventTransfer struct {
+	From  srprimitives.AccountId
+	To    srprimitives.AccountId
+	Value Balance
+	Fee   Balance
+}
+
+func (e EventTransfer) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(2)
+	e.From.ParityEncode(pe)
+	e.To.ParityEncode(pe)
+	e.Value.ParityEncode(pe)
+	e.Fee.ParityEncode(pe)
+}
+
+func (m *Module) DecodeEvent(pd codec.Decoder) support.RawEvent {
+	b := pd.DecodeByte()
+	switch b {
+	case 0:
+		who := m.TypeParamsFactory.DecodeAccountId(pd)
+		return EventNewAccount{who, m.BalanceFactory.DecodeBalance(pd)}
+	case 1:
+		return EventReapedAccount{m.TypeParamsFactory.DecodeAccountId(pd)}
+	case 2:
+		from := m.TypeParamsFactory.DecodeAccountId(pd)
+		to := m.TypeParamsFactory.DecodeAccountId(pd)
+		value := m.BalanceFactory.DecodeBalance(pd)
+		return EventTransfer{from, to, value, m.BalanceFactory.DecodeBalance(pd)}
+	}
+	panic(primitives.InvalidEnum(b, "balances Event"))
+}
+
+func (m *Module) CallMetadata() []metadata.FunctionMetadata {
+	return []metadata.FunctionMetadata{
+		{uint16(TransferId), "transfer", []metadata.FunctionArgumentMetadata{
+			{"dest", "<T::Lookup as StaticLookup>::Source"},
+			{"value", "Compact<T::Balance>"},
+		}, []string{"Transfer some liquid free balance to another staker."}},
+		{uint16(SetBalanceId), "set_balance", []metadata.FunctionArgumentMetadata{
+			{"who", "<T::Lookup as StaticLookup>::Source"},
+			{"free", "Compact<T::Balance>"},
+			{"reserved", "Compact<T::Balance>"},
+		}, []string{"Set the balances of a given account."}},
+	}
+}
+
+func (m *Module) StorageMetadata() metadata.StorageMetadata {
+	return metadata.StorageMetadata{"Balances", []metadata.StorageFunctionMetadata{
+		m.TotalIssuanceStore.Metadata("T::Balance", "The total amount of stake on the system."),
+		m.ExistentialDepositStore.Metadata("T::Balance", "The minimum amount allowed to keep an account open."),
+		m.TransferFeeStore.Metadata("T::Balance", "The fee required to make a transfer."),
+		m.CreationFeeStore.Metadata("T::Balance", "The fee required to create an account."),
+		m.TransactionBaseFeeStore.Metadata("T::Balance", "The fee to be paid for making a transaction; the base."),
+		m.TransactionByteFeeStore.Metadata("T::Balance", "The fee to be paid for making a transaction; the per-byte portion."),
+		m.FreeBalanceStore.Metadata("T::AccountId", "T::Balance", "The 'free' balance of a given account."),
+		m.ReservedBalanceStore.Metadata("T::AccountId", "T::Balance", "The amount of the balance of a given account that is externally reserved."),
+	}}
+}
+
+func (m *Module) EventMetadata() []metadata.EventMetadata {
+	return []metadata.EventMetadata{
+		{"NewAccount", []string{"AccountId", "Balance"}, []string{"A new account was created."}},
+		{"ReapedAccount", []string{"AccountId"}, []string{"An account was reaped."}},
+		{"Transfer", []string{"AccountId", "AccountId", "Balance", "Balance"}, []string{"Transfer succeeded (from, to, value, fees)."}},
+	}
+}
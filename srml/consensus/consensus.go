@@ -0,0 +1,574 @@
+package consensus
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/inherents"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srio"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/storage"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/system"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// The consensus module manages the authority set for the native code
+// and the runtime code itself (`:code`).
+
+// Well known (unhashed) storage keys, shared with the native code.
+var (
+	/// Prefix of the authority set items, followed by the encoded u32 index of the authority.
+	AuthorityAt = []byte(":auth:")
+	/// Number of authorities.
+	AuthorityCount = []byte(":auth:len")
+	/// Wasm code of the runtime.
+	Code = []byte(":code")
+	/// Number of wasm linear memory pages required for execution of the runtime.
+	HeapPages = []byte(":heappages")
+)
+
+/// The identifier for consensus inherents.
+var InherentIdentifier = inherents.InherentIdentifier{'o', 'f', 'f', 'l', 'r', 'e', 'p', '0'}
+
+/// The list of authorities, stored in the unhashed `:auth:` keys.
+type Authorities []srprimitives.AuthorityId
+
+func (a Authorities) ParityEncode(pe codec.Encoder) {
+	pe.EncodeCollection(len(a), func(i int) { a[i].ParityEncode(pe) })
+}
+
+func (a Authorities) equals(other Authorities) bool {
+	return bytes.Equal(codec.ToBytes(a), codec.ToBytes(other))
+}
+
+/// A report of offline authorities, passed to the `note_offline` call.
+type OfflineReport interface {
+	codec.Encodeable
+}
+
+/// Handling of the offline reports, both from the `note_offline` call and the inherent data.
+type InherentOfflineReport interface {
+	DecodeReport(pd codec.Decoder) OfflineReport
+	/// Whether an inherent is empty and doesn't need to be included.
+	IsEmpty(report OfflineReport) bool
+	/// Handle the report.
+	HandleReport(report OfflineReport)
+	/// Whether two reports are compatible.
+	CheckInherent(contained OfflineReport, expected OfflineReport) error
+}
+
+/// No offline reports at all (`()` in the node template).
+type NoOfflineReport struct{}
+
+type emptyReport struct{}
+
+func (_ emptyReport) ParityEncode(pe codec.Encoder) {}
+
+func (_ NoOfflineReport) DecodeReport(pd codec.Decoder) OfflineReport { return emptyReport{} }
+
+func (_ NoOfflineReport) IsEmpty(report OfflineReport) bool { return true }
+
+func (_ NoOfflineReport) HandleReport(report OfflineReport) {}
+
+/// Rejects the `note_offline` calls, as the offline reports are not supported.
+func (_ NoOfflineReport) CheckInherent(contained OfflineReport, expected OfflineReport) error {
+	return errors.New("Explicit reporting not allowed")
+}
+
+/// Something which can handle the offline authorities, by their index in the authority set.
+type OnOfflineReport interface {
+	HandleReport(offline []uint32)
+}
+
+/// Indices of the offline authorities.
+type OfflineIndices []uint32
+
+func (o OfflineIndices) ParityEncode(pe codec.Encoder) {
+	pe.EncodeCollection(len(o), func(i int) { pe.EncodeUint32(o[i]) })
+}
+
+func decodeOfflineIndices(pd codec.Decoder) OfflineIndices {
+	var o OfflineIndices
+	pd.DecodeCollection(
+		func(n int) { o = make(OfflineIndices, n) },
+		func(i int) { o[i] = pd.DecodeUint32() },
+	)
+	return o
+}
+
+/// A variant of the offline report with instant finality: the offline authorities
+/// are reported by their indices.
+type InstantFinalityReportVec struct {
+	OnOfflineReport OnOfflineReport
+}
+
+func (_ InstantFinalityReportVec) DecodeReport(pd codec.Decoder) OfflineReport {
+	return decodeOfflineIndices(pd)
+}
+
+func (_ InstantFinalityReportVec) IsEmpty(report OfflineReport) bool {
+	return len(report.(OfflineIndices)) == 0
+}
+
+func (r InstantFinalityReportVec) HandleReport(report OfflineReport) {
+	r.OnOfflineReport.HandleReport(report.(OfflineIndices))
+}
+
+func (_ InstantFinalityReportVec) CheckInherent(contained OfflineReport, expected OfflineReport) error {
+	for _, n := range contained.(OfflineIndices) {
+		found := false
+		for _, e := range expected.(OfflineIndices) {
+			if n == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("Node we believe online marked offline")
+		}
+	}
+	return nil
+}
+
+type Module struct {
+	support.BaseModule
+	TypeParamsFactory support.TypeParamsFactory
+	System            *system.Module
+	/// The identifier we use to refer to authorities.
+	AuthorityIdFactory func() srprimitives.AuthorityId
+	/// Defaults to NoOfflineReport.
+	InherentOfflineReport InherentOfflineReport
+
+	/// The authorities at the start of the block, if they have been changed since.
+	OriginalAuthoritiesStore storage.SimpleStorageValue
+}
+
+func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
+	m.TypeParamsFactory = r
+	if m.InherentOfflineReport == nil {
+		m.InherentOfflineReport = NoOfflineReport{}
+	}
+
+	m.OriginalAuthoritiesStore = storage.SimpleStorageValue{
+		[]byte("Consensus OriginalAuthorities"),
+		"",
+		func() storage.StoredValue { return nil },
+		func(pd codec.Decoder) storage.StoredValue { return m.decodeAuthorities(pd) },
+	}
+}
+
+/// Initial state of the module
+type GenesisConfig struct {
+	Authorities Authorities
+	/// Wasm code of the runtime
+	Code []byte
+}
+
+/// Writes the initial state of the module into the storage.
+func (m *Module) BuildStorage(config GenesisConfig) {
+	for i, a := range config.Authorities {
+		srio.UnhashedPut(authorityKey(uint32(i)), codec.ToBytes(a))
+	}
+	setAuthorityCount(uint32(len(config.Authorities)))
+	srio.UnhashedPut(Code, config.Code)
+}
+
+func (m *Module) decodeAuthorities(pd codec.Decoder) Authorities {
+	var a Authorities
+	pd.DecodeCollection(
+		func(n int) { a = make(Authorities, n) },
+		func(i int) { a[i] = m.decodeAuthorityId(pd) },
+	)
+	return a
+}
+
+func (m *Module) decodeAuthorityId(pd codec.Decoder) srprimitives.AuthorityId {
+	a := m.AuthorityIdFactory()
+	a.ParityDecode(pd)
+	return a
+}
+
+func authorityKey(index uint32) []byte {
+	var buffer = bytes.NewBuffer(append([]byte{}, AuthorityAt...))
+	codec.Encoder{buffer}.EncodeUint32(index)
+	return buffer.Bytes()
+}
+
+func authorityCount() uint32 {
+	ok, value := srio.UnhashedGet(AuthorityCount)
+	if !ok {
+		return 0
+	}
+	return codec.Decoder{bytes.NewBuffer(value)}.DecodeUint32()
+}
+
+func setAuthorityCount(count uint32) {
+	srio.UnhashedPut(AuthorityCount, codec.ToBytesCustom(func(pe codec.Encoder) { pe.EncodeUint32(count) }))
+}
+
+func (m *Module) authority(index uint32) srprimitives.AuthorityId {
+	a := m.AuthorityIdFactory()
+	ok, value := srio.UnhashedGet(authorityKey(index))
+	if ok {
+		a.ParityDecode(codec.Decoder{bytes.NewBuffer(value)})
+	}
+	return a
+}
+
+/// Get the current set of authorities. These are the session keys.
+func (m *Module) Authorities() Authorities {
+	count := authorityCount()
+	a := make(Authorities, count)
+	for i := uint32(0); i < count; i++ {
+		a[i] = m.authority(i)
+	}
+	return a
+}
+
+/// Set the current set of authorities' session keys.
+///
+/// Called by `NextSession` only.
+func (m *Module) SetAuthorities(authorities Authorities) {
+	current := m.Authorities()
+	if !current.equals(authorities) {
+		m.saveOriginalAuthorities(current)
+		for i, a := range authorities {
+			srio.UnhashedPut(authorityKey(uint32(i)), codec.ToBytes(a))
+		}
+		for i := uint32(len(authorities)); i < uint32(len(current)); i++ {
+			srio.UnhashedKill(authorityKey(i))
+		}
+		setAuthorityCount(uint32(len(authorities)))
+	}
+}
+
+/// Set the current set of authorities' session keys.
+///
+/// Called by `NextSession` only.
+func (m *Module) SetAuthorityCount(count uint32) {
+	m.saveOriginalAuthorities(nil)
+	// As StorageVec::set_count, remove the authorities beyond the new count
+	old := authorityCount()
+	for i := count; i < old; i++ {
+		srio.UnhashedKill(authorityKey(i))
+	}
+	setAuthorityCount(count)
+}
+
+/// Set a single authority by index.
+func (m *Module) SetAuthority(index uint32, key srprimitives.AuthorityId) {
+	current := m.authority(index)
+	if !bytes.Equal(codec.ToBytes(current), codec.ToBytes(key)) {
+		m.saveOriginalAuthorities(nil)
+		srio.UnhashedPut(authorityKey(index), codec.ToBytes(key))
+	}
+}
+
+/// Save original authorities set, unless already saved. The current authorities
+/// are read when nil is passed.
+func (m *Module) saveOriginalAuthorities(current Authorities) {
+	if m.OriginalAuthoritiesStore.Get() != nil {
+		return
+	}
+	if current == nil {
+		current = m.Authorities()
+	}
+	m.OriginalAuthoritiesStore.Put(current)
+}
+
+func (m *Module) OnFinalise(n srprimitives.BlockNumber) {
+	original := m.OriginalAuthoritiesStore.Take()
+	if original != nil {
+		current := m.Authorities()
+		if !current.equals(original.(Authorities)) {
+			m.System.DepositLog(srprimitives.AuthoritiesChange(current))
+		}
+	}
+}
+
+// Method IDs
+const (
+	ReportMisbehaviorId byte = 0
+	NoteOfflineId       byte = 1
+	RemarkId            byte = 2
+	SetHeapPagesId      byte = 3
+	SetCodeId           byte = 4
+	SetStorageId        byte = 5
+	KillStorageId       byte = 6
+)
+
+/// Report some misbehavior.
+type ReportMisbehaviorCall struct {
+	m      *Module
+	Report []byte
+}
+
+func (c ReportMisbehaviorCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{ReportMisbehaviorId, c}
+}
+
+// Encodes the arguments of the call
+func (c ReportMisbehaviorCall) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByteSlice(c.Report)
+}
+
+func (c ReportMisbehaviorCall) Dispatch(o srprimitives.Origin) error {
+	_, err := system.EnsureSigned(o)
+	return err
+}
+
+/// Note the previous block's validator missed their opportunity to propose a block.
+type NoteOfflineCall struct {
+	m       *Module
+	Offline OfflineReport
+}
+
+func (m *Module) NoteOfflineCall(offline OfflineReport) NoteOfflineCall {
+	return NoteOfflineCall{m, offline}
+}
+
+func (c NoteOfflineCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{NoteOfflineId, c.Offline}
+}
+
+func (c NoteOfflineCall) Dispatch(o srprimitives.Origin) error {
+	if err := system.EnsureInherent(o); err != nil {
+		return err
+	}
+	c.m.InherentOfflineReport.HandleReport(c.Offline)
+	return nil
+}
+
+/// Make some on-chain remark.
+type RemarkCall struct {
+	m      *Module
+	Remark []byte
+}
+
+func (c RemarkCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{RemarkId, c}
+}
+
+// Encodes the arguments of the call
+func (c RemarkCall) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByteSlice(c.Remark)
+}
+
+func (c RemarkCall) Dispatch(o srprimitives.Origin) error {
+	_, err := system.EnsureSigned(o)
+	return err
+}
+
+/// Set the number of pages in the WebAssembly environment's heap.
+type SetHeapPagesCall struct {
+	m     *Module
+	Pages uint64
+}
+
+func (m *Module) SetHeapPagesCall(pages uint64) SetHeapPagesCall {
+	return SetHeapPagesCall{m, pages}
+}
+
+func (c SetHeapPagesCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{SetHeapPagesId, c}
+}
+
+// Encodes the arguments of the call
+func (c SetHeapPagesCall) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint64(c.Pages)
+}
+
+func (c SetHeapPagesCall) Dispatch(o srprimitives.Origin) error {
+	if err := system.EnsureRoot(o); err != nil {
+		return err
+	}
+	srio.UnhashedPut(HeapPages, codec.ToBytesCustom(func(pe codec.Encoder) { pe.EncodeUint64(c.Pages) }))
+	return nil
+}
+
+/// Set the new code.
+type SetCodeCall struct {
+	m   *Module
+	New []byte
+}
+
+func (m *Module) SetCodeCall(new []byte) SetCodeCall {
+	return SetCodeCall{m, new}
+}
+
+func (c SetCodeCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{SetCodeId, c}
+}
+
+// Encodes the arguments of the call
+func (c SetCodeCall) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByteSlice(c.New)
+}
+
+func (c SetCodeCall) Dispatch(o srprimitives.Origin) error {
+	if err := system.EnsureRoot(o); err != nil {
+		return err
+	}
+	srio.UnhashedPut(Code, c.New)
+	return nil
+}
+
+/// A raw storage key and its value.
+type KeyValue struct {
+	Key   []byte
+	Value []byte
+}
+
+/// Set some items of storage.
+type SetStorageCall struct {
+	m     *Module
+	Items []KeyValue
+}
+
+func (c SetStorageCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{SetStorageId, c}
+}
+
+// Encodes the arguments of the call
+func (c SetStorageCall) ParityEncode(pe codec.Encoder) {
+	pe.EncodeCollection(len(c.Items), func(i int) {
+		pe.EncodeByteSlice(c.Items[i].Key)
+		pe.EncodeByteSlice(c.Items[i].Value)
+	})
+}
+
+func (c SetStorageCall) Dispatch(o srprimitives.Origin) error {
+	if err := system.EnsureRoot(o); err != nil {
+		return err
+	}
+	for _, item := range c.Items {
+		srio.UnhashedPut(item.Key, item.Value)
+	}
+	return nil
+}
+
+/// Kill some items from storage.
+type KillStorageCall struct {
+	m    *Module
+	Keys [][]byte
+}
+
+func (c KillStorageCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{KillStorageId, c}
+}
+
+// Encodes the arguments of the call
+func (c KillStorageCall) ParityEncode(pe codec.Encoder) {
+	pe.EncodeCollection(len(c.Keys), func(i int) { pe.EncodeByteSlice(c.Keys[i]) })
+}
+
+func (c KillStorageCall) Dispatch(o srprimitives.Origin) error {
+	if err := system.EnsureRoot(o); err != nil {
+		return err
+	}
+	for _, key := range c.Keys {
+		srio.UnhashedKill(key)
+	}
+	return nil
+}
+
+func (m *Module) DecodeCall(pd codec.Decoder) srprimitives.Callable {
+	b := pd.DecodeByte()
+	switch b {
+	case ReportMisbehaviorId:
+		return ReportMisbehaviorCall{m, pd.DecodeByteSlice()}
+	case NoteOfflineId:
+		return NoteOfflineCall{m, m.InherentOfflineReport.DecodeReport(pd)}
+	case RemarkId:
+		return RemarkCall{m, pd.DecodeByteSlice()}
+	case SetHeapPagesId:
+		return SetHeapPagesCall{m, pd.DecodeUint64()}
+	case SetCodeId:
+		return SetCodeCall{m, pd.DecodeByteSlice()}
+	case SetStorageId:
+		var items []KeyValue
+		pd.DecodeCollection(
+			func(n int) { items = make([]KeyValue, n) },
+			func(i int) { items[i] = KeyValue{pd.DecodeByteSlice(), pd.DecodeByteSlice()} },
+		)
+		return SetStorageCall{m, items}
+	case KillStorageId:
+		var keys [][]byte
+		pd.DecodeCollection(
+			func(n int) { keys = make([][]byte, n) },
+			func(i int) { keys[i] = pd.DecodeByteSlice() },
+		)
+		return KillStorageCall{m, keys}
+	}
+	panic(primitives.InvalidEnum(b, "consensus Call"))
+}
+
+func (m *Module) CallableBelongsToThisModule(c srprimitives.Callable) bool {
+	switch c.(type) {
+	case ReportMisbehaviorCall, NoteOfflineCall, RemarkCall, SetHeapPagesCall,
+		SetCodeCall, SetStorageCall, KillStorageCall:
+		return true
+	}
+	return false
+}
+
+// Implements inherents.ProvideInherent
+
+func (m *Module) InherentIdentifier() inherents.InherentIdentifier {
+	return InherentIdentifier
+}
+
+func (m *Module) extractInherentData(data *inherents.InherentData) (bool, OfflineReport) {
+	ok, report := data.GetData(InherentIdentifier, func(pd codec.Decoder) interface{} {
+		return m.InherentOfflineReport.DecodeReport(pd)
+	})
+	if !ok {
+		return false, nil
+	}
+	return true, report.(OfflineReport)
+}
+
+func (m *Module) CreateInherent(data *inherents.InherentData) srprimitives.Callable {
+	ok, report := m.extractInherentData(data)
+	if !ok || m.InherentOfflineReport.IsEmpty(report) {
+		return nil
+	}
+	return NoteOfflineCall{m, report}
+}
+
+func (m *Module) CheckInherent(call srprimitives.Callable, data *inherents.InherentData) inherents.CheckInherentError {
+	noteOffline, ok := call.(NoteOfflineCall)
+	if !ok {
+		return nil
+	}
+	ok, expected := m.extractInherentData(data)
+	if !ok {
+		return inherents.MakeFatalError("No `offline_report` found in the inherent data!")
+	}
+	err := m.InherentOfflineReport.CheckInherent(noteOffline.Offline, expected)
+	if err != nil {
+		return inherents.MakeFatalError(err.Error())
+	}
+	return nil
+}
+
+func (m *Module) CallMetadata() []metadata.FunctionMetadata {
+	return []metadata.FunctionMetadata{
+		{uint16(ReportMisbehaviorId), "report_misbehavior", []metadata.FunctionArgumentMetadata{{"_report", "Vec<u8>"}}, []string{"Report some misbehavior."}},
+		{uint16(NoteOfflineId), "note_offline", []metadata.FunctionArgumentMetadata{{"offline", "<T::InherentOfflineReport as InherentOfflineReport>::Inherent"}}, []string{"Note the previous block's validator missed their opportunity to propose a block."}},
+		{uint16(RemarkId), "remark", []metadata.FunctionArgumentMetadata{{"_remark", "Vec<u8>"}}, []string{"Make some on-chain remark."}},
+		{uint16(SetHeapPagesId), "set_heap_pages", []metadata.FunctionArgumentMetadata{{"pages", "u64"}}, []string{"Set the number of pages in the WebAssembly environment's heap."}},
+		{uint16(SetCodeId), "set_code", []metadata.FunctionArgumentMetadata{{"new", "Vec<u8>"}}, []string{"Set the new code."}},
+		{uint16(SetStorageId), "set_storage", []metadata.FunctionArgumentMetadata{{"items", "Vec<KeyValue>"}}, []string{"Set some items of storage."}},
+		{uint16(KillStorageId), "kill_storage", []metadata.FunctionArgumentMetadata{{"keys", "Vec<Key>"}}, []string{"Kill some items from storage."}},
+	}
+}
+
+func (m *Module) StorageMetadata() metadata.StorageMetadata {
+	return metadata.StorageMetadata{"Consensus", []metadata.StorageFunctionMetadata{
+		m.OriginalAuthoritiesStore.Metadata("Vec<T::SessionKey>", "The authorities at the start of the block, if they have been changed since."),
+	}}
+}
@@ -15,7 +15,14 @@ import (
 type Executive struct {
 	SystemModule system.Module
 	Payment      srprimitives.MakePayment
-	Finalization srprimitives.OnFinalise
+	AllModules   AllModules
+}
+
+/// Per-block hooks of all the modules in the runtime, see runtime.Runtime
+type AllModules interface {
+	srprimitives.OnInitialise
+	srprimitives.OnFinalise
+	srprimitives.OffchainWorker
 }
 
 type ApplyError interface {
@@ -31,27 +38,37 @@ const (
 	ErrStale
 	ErrFuture
 	ErrCantPay
+	ErrFullBlock
+)
+
+/// Resource limits of a block. Applying an extrinsic that would exceed them
+/// fails with ApplyErrorFullBlock.
+const (
+	/// Maximum total weight of the extrinsics in a block.
+	MaxTransactionsWeight srprimitives.Weight = 4 * 1024 * 1024
+	/// Maximum total encoded length of the extrinsics in a block.
+	MaxTransactionsSize uint32 = 4 * 1024 * 1024
 )
 
 /// Start the execution of a particular block.
 func (e *Executive) InitialiseBlock(header *srprimitives.Header) {
 	e.SystemModule.Initialise(header.Number, header.ParentHash, header.ExtrinsicsRoot)
+	e.AllModules.OnInitialise(header.Number)
 }
 
 func (e *Executive) InitialChecks(block *srprimitives.Block) {
 	header := &block.Header
 	n := header.Number
 	gohelpers.Assert(n.GreaterThan(e.SystemModule.TypeParamsFactory.BlockNumber(0)) &&
-		e.SystemModule.BlockHashStore.Get(n.MinusOne()) == header.ParentHash,
+		encodedEqual(e.SystemModule.BlockHashStore.Get(n.MinusOne()).(srprimitives.HashOutput), header.ParentHash),
 		"Parent hash should be valid.",
 	)
-	extrinsics := make([]codec.Encodeable, len(block.Extrinsics))
+	extrinsics := make([][]byte, len(block.Extrinsics))
 	for i := range extrinsics {
-		extrinsics[i] = block.Extrinsics[i].EncodeableEnum()
+		extrinsics[i] = codec.ToBytes(block.Extrinsics[i])
 	}
-	xtsRoot := primitives.H256(srio.EnumeratedTrieRootBlake256(extrinsics))
-	gohelpers.Assert(*header.ExtrinsicsRoot.(*primitives.H256) == xtsRoot,
-		"Transaction trie root must be valid.")
+	xtsRoot := e.SystemModule.TypeParamsFactory.Hasher().EnumeratedTrieRoot(extrinsics)
+	gohelpers.Assert(encodedEqual(header.ExtrinsicsRoot, xtsRoot), "Transaction trie root must be valid.")
 }
 
 func (e *Executive) ExecuteBlock(block *srprimitives.Block) {
@@ -61,14 +78,14 @@ func (e *Executive) ExecuteBlock(block *srprimitives.Block) {
 		e.applyExtrinsicNoNote(ext)
 	}
 	e.SystemModule.NoteFinishedExtrinsics()
-	e.Finalization.OnFinalise(block.Header.Number)
+	e.AllModules.OnFinalise(block.Header.Number)
 
 	e.finalChecks(&block.Header)
 }
 
 func (e *Executive) FinaliseBlock() srprimitives.Header {
 	e.SystemModule.NoteFinishedExtrinsics()
-	e.Finalization.OnFinalise(e.SystemModule.NumberStore.Get().(srprimitives.BlockNumber))
+	e.AllModules.OnFinalise(e.SystemModule.NumberStore.Get().(srprimitives.BlockNumber))
 
 	// setup extrinsics
 	e.SystemModule.DeriveExtrinsics()
@@ -79,7 +96,7 @@ func (e *Executive) FinaliseBlock() srprimitives.Header {
 /// This doesn't attempt to validate anything regarding the block, but it builds a list of uxt
 /// hashes.
 func (e *Executive) ApplyExtrinsic(uxt srprimitives.Extrinsic) srprimitives.ApplyResult {
-	encoded := codec.ToBytes(uxt.EncodeableEnum())
+	encoded := codec.ToBytes(uxt)
 	encodedLen := len(encoded)
 	e.SystemModule.NoteExtrinsic(encoded)
 	code, _ := e.applyExtrinsicNoNoteWithLen(uxt, uintptr(encodedLen))
@@ -93,16 +110,18 @@ func (e *Executive) ApplyExtrinsic(uxt srprimitives.Extrinsic) srprimitives.Appl
 	case ErrBadSignature:
 		return srprimitives.ApplyErrorBadSignature
 	case ErrStale:
-		return srprimitives.ApplyErrorBadSignature
+		return srprimitives.ApplyErrorStale
 	case ErrFuture:
 		return srprimitives.ApplyErrorFuture
+	case ErrFullBlock:
+		return srprimitives.ApplyErrorFullBlock
 	}
 	panic("Unknown code: " + strconv.Itoa(int(code)))
 }
 
 /// Apply an extrinsic inside the block execution function.
 func (e *Executive) applyExtrinsicNoNote(uxt srprimitives.Extrinsic) {
-	encoded := codec.ToBytes(uxt.EncodeableEnum())
+	encoded := codec.ToBytes(uxt)
 	encodedLen := len(encoded)
 	code, msg := e.applyExtrinsicNoNoteWithLen(uxt, uintptr(encodedLen))
 	switch code {
@@ -116,6 +135,8 @@ func (e *Executive) applyExtrinsicNoNote(uxt srprimitives.Extrinsic) {
 		panic("All extrinsics should be properly signed")
 	case ErrStale, ErrFuture:
 		panic("All extrinsics should have the correct nonce")
+	case ErrFullBlock:
+		panic("Extrinsics should not exceed block limit")
 	}
 }
 
@@ -127,17 +148,23 @@ func (e *Executive) applyExtrinsicNoNoteWithLen(uxt srprimitives.Extrinsic, enco
 		return ErrBadSignature, err.Error()
 	}
 
+	// Check the weight and length of the block if that extrinsic is applied.
+	weight := xt.Weight(encodedLen)
+	if uint64(e.SystemModule.AllExtrinsicsWeight())+uint64(weight) > uint64(MaxTransactionsWeight) ||
+		uint64(e.SystemModule.AllExtrinsicsLen())+uint64(encodedLen) > uint64(MaxTransactionsSize) {
+		return ErrFullBlock, "Transaction would exhaust the block resources"
+	}
+
 	if xt.SignatureAccountID != nil {
 		sender := xt.SignatureAccountID
 		index := xt.SignatureIndex
 		// check index
 		expectedIndex := e.SystemModule.AccountNonceStore.Get(sender).(srprimitives.Index)
-		if index != expectedIndex {
-			if index.LessThan(expectedIndex) {
-				return ErrStale, ""
-			} else {
-				return ErrFuture, ""
-			}
+		if index.LessThan(expectedIndex) {
+			return ErrStale, ""
+		}
+		if index.GreaterThan(expectedIndex) {
+			return ErrFuture, ""
 		}
 
 		// pay any fees.
@@ -153,8 +180,8 @@ func (e *Executive) applyExtrinsicNoNoteWithLen(uxt srprimitives.Extrinsic, enco
 
 	// decode parameters and dispatch
 	call, accountID := xt.Deconstruct()
-	err = call.Dispatch(accountID)
-	e.SystemModule.NoteAppliedExtrinsic(err)
+	err = call.Dispatch(system.OptionAccountIdToOrigin(accountID != nil, accountID))
+	e.SystemModule.NoteAppliedExtrinsic(err, weight, uint32(encodedLen))
 
 	if err == nil {
 		return OkSuccess, ""
@@ -174,12 +201,22 @@ func (e *Executive) finalChecks(header *srprimitives.Header) {
 
 	for i, headerItem := range header.Digest.Logs {
 		computedItem := newHeader.Digest.Logs[i]
-		gohelpers.Assert(headerItem == computedItem, "Digest item must match that calculated.")
+		gohelpers.Assert(encodedEqual(headerItem, computedItem), "Digest item must match that calculated.")
 	}
 
 	// check storage root.
-	storageRoot := srio.StorageRoot()
-	gohelpers.Assert(header.StateRoot == storageRoot, "Storage root must match that calculated.")
+	storageRoot := e.SystemModule.TypeParamsFactory.Hasher().StorageRoot()
+	gohelpers.Assert(encodedEqual(header.StateRoot, storageRoot), "Storage root must match that calculated.")
+}
+
+// Values of the type parameters are mostly pointers, so they are compared by their encoding
+func encodedEqual(a codec.Encodeable, b codec.Encodeable) bool {
+	return bytes.Equal(codec.ToBytes(a), codec.ToBytes(b))
+}
+
+/// Start an off-chain worker and generate extrinsics.
+func (e *Executive) GenerateExtrinsics(n srprimitives.BlockNumber) {
+	e.AllModules.GenerateExtrinsics(n)
 }
 
 /// Check a given transaction for validity. This doesn't execute any
@@ -187,12 +224,12 @@ func (e *Executive) finalChecks(header *srprimitives.Header) {
 ///
 /// Changes made to the storage should be discarded.
 func (e *Executive) ValidateTransaction(uxt srprimitives.Extrinsic) srprimitives.TransactionValidity {
-	encoded := codec.ToBytes(uxt.EncodeableEnum())
+	encoded := codec.ToBytes(uxt)
 	encodedLen := len(encoded)
 
 	xt, err := uxt.(srprimitives.Checkable).Check(e.SystemModule.TypeParamsFactory.DefaultContext())
 	if err != nil {
-		if err.Error() == "invalid account index" {
+		if err == srprimitives.CheckErrorInvalidAccountIndex {
 			// An unknown account index implies that the transaction may yet become valid.
 			return srprimitives.TransactionValidityUnknown{}
 		}
@@ -237,12 +274,22 @@ func (e *Executive) ValidateTransaction(uxt srprimitives.Extrinsic) srprimitives
 		prov := srprimitives.TransactionTag(buffer.Bytes())
 
 		return srprimitives.TransactionValidityValid{
-			Priority:  srprimitives.TransactionPriority(encodedLen),
+			Priority:  srprimitives.TransactionPriority(xt.Weight(uintptr(encodedLen))),
 			Requires:  deps,
 			Provides:  []srprimitives.TransactionTag{prov},
-			Longevity: srprimitives.TransactionLongevityMaxValue,
+			Longevity: e.longevity(uxt),
 		}
 	} else {
 		return srprimitives.TransactionValidityInvalid{}
 	}
 }
+
+/// The number of blocks the transaction remains valid for, derived from its era.
+func (e *Executive) longevity(uxt srprimitives.Extrinsic) srprimitives.TransactionLongevity {
+	mortal, ok := uxt.(srprimitives.MortalExtrinsic)
+	if !ok || mortal.Era().IsImmortal() {
+		return srprimitives.TransactionLongevityMaxValue
+	}
+	current := e.SystemModule.NumberStore.Get().(srprimitives.BlockNumber).AsUint64()
+	return srprimitives.TransactionLongevity(mortal.Era().Death(current) - current)
+}
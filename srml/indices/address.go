@@ -1,7 +1,66 @@
 package indices
 
-type Address interface {
-	ImplementsAddress()
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// Declared in srprimitives, since extrinsics (de)serialize it
+type Address = srprimitives.Address
+
+/// It's an account ID (pubkey).
+type AddressId struct {
+	Id srprimitives.AccountId
+}
+
+func (_ AddressId) ImplementsAddress() {}
+
+func (a AddressId) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(0xff)
+	a.Id.ParityEncode(pe)
 }
 
-// TODO: AddressId, AddresIndex, codec
+/// It's an account index.
+type AddressIndex AccountIndex
+
+func (_ AddressIndex) ImplementsAddress() {}
+
+func (a AddressIndex) ParityEncode(pe codec.Encoder) {
+	switch {
+	case a > 0xffff:
+		pe.EncodeByte(0xfd)
+		pe.EncodeUint32(uint32(a))
+	case a >= 0xf0:
+		pe.EncodeByte(0xfc)
+		pe.EncodeByte(byte(a))
+		pe.EncodeByte(byte(a >> 8))
+	default:
+		pe.EncodeByte(byte(a))
+	}
+}
+
+/// Decode an address: a single byte for small indices, or a prefix byte
+/// (0xfc: 16-bit index, 0xfd: 32-bit index, 0xfe: 64-bit index, 0xff: account id)
+/// followed by the value. Indices must use the shortest encoding.
+func DecodeAddress(pd codec.Decoder, decodeAccountId func(pd codec.Decoder) srprimitives.AccountId) Address {
+	b := pd.DecodeByte()
+	switch {
+	case b <= 0xef:
+		return AddressIndex(b)
+	case b == 0xfc:
+		i := uint32(pd.DecodeByte()) | uint32(pd.DecodeByte())<<8
+		if i > 0xef {
+			return AddressIndex(i)
+		}
+	case b == 0xfd:
+		i := pd.DecodeUint32()
+		if i > 0xffff {
+			return AddressIndex(i)
+		}
+	case b == 0xfe:
+		// AccountIndex is 32-bit, so a 64-bit index is never valid
+	case b == 0xff:
+		return AddressId{decodeAccountId(pd)}
+	}
+	panic("Invalid address encoding")
+}
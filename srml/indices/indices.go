@@ -0,0 +1,230 @@
+package indices
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/storage"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/system"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// An index is a short form of an address. This module handles allocation
+// of indices for newly created accounts.
+
+/// The type for recording indexing into the account enumeration.
+type AccountIndex uint32
+
+func (i AccountIndex) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint32(uint32(i))
+}
+
+/// Number of account IDs stored per enum set.
+const EnumSetSize = 64
+
+/// Resolve an account index hint from an account id, used to reuse the indices of dead accounts.
+type ResolveHint interface {
+	ResolveHint(who srprimitives.AccountId) (bool, AccountIndex)
+}
+
+/// Simple resolver which takes the first two bytes of the encoded account id
+/// (little-endian) as the index hint.
+type SimpleResolveHint struct{}
+
+func (_ SimpleResolveHint) ResolveHint(who srprimitives.AccountId) (bool, AccountIndex) {
+	e := codec.ToBytes(who)
+	return true, AccountIndex(uint32(e[0]) + uint32(e[1])*256)
+}
+
+type Module struct {
+	support.BaseModule
+	TypeParamsFactory support.TypeParamsFactory
+	System            *system.Module
+	/// Use the standard means of resolving an index hint from an id.
+	ResolveHint ResolveHint
+	/// Determine whether an account is dead.
+	IsDeadAccount system.IsDeadAccount
+
+	/// The next free enumeration set.
+	NextEnumSetStore storage.SimpleStorageValue
+	/// The enumeration sets.
+	EnumSetStore storage.MapStorageValue
+}
+
+func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
+	m.TypeParamsFactory = r
+
+	m.NextEnumSetStore = storage.SimpleStorageValue{
+		[]byte("Indices NextEnumSet"),
+		"",
+		func() storage.StoredValue { return AccountIndex(0) },
+		func(pd codec.Decoder) storage.StoredValue {
+			return AccountIndex(pd.DecodeUint32())
+		},
+	}
+	m.EnumSetStore = storage.MapStorageValue{
+		[]byte("Indices EnumSet"),
+		"",
+		func() storage.StoredValue { return AccountIds{} },
+		func(pd codec.Decoder) storage.StoredValue {
+			var ids AccountIds
+			pd.DecodeCollection(
+				func(n int) { ids = make(AccountIds, n) },
+				func(i int) { ids[i] = m.TypeParamsFactory.DecodeAccountId(pd) },
+			)
+			return ids
+		},
+	}
+}
+
+/// Initial state of the module
+type GenesisConfig struct {
+	/// The accounts which get the first indices
+	Ids AccountIds
+}
+
+/// Writes the initial state of the module into the storage.
+func (m *Module) BuildStorage(config GenesisConfig) {
+	setCount := (len(config.Ids) + EnumSetSize - 1) / EnumSetSize
+	for i := 0; i < setCount; i++ {
+		end := (i + 1) * EnumSetSize
+		if end > len(config.Ids) {
+			end = len(config.Ids)
+		}
+		m.EnumSetStore.Insert(AccountIndex(i), config.Ids[i*EnumSetSize:end])
+	}
+	m.NextEnumSetStore.Put(AccountIndex(len(config.Ids) / EnumSetSize))
+}
+
+type AccountIds []srprimitives.AccountId
+
+func (a AccountIds) ParityEncode(pe codec.Encoder) {
+	pe.EncodeCollection(len(a), func(i int) { a[i].ParityEncode(pe) })
+}
+
+/// Lookup an account index to get an Id, if there's one there.
+func (m *Module) LookupIndex(index AccountIndex) (bool, srprimitives.AccountId) {
+	set := m.EnumSetStore.Get(index / EnumSetSize).(AccountIds)
+	i := int(index % EnumSetSize)
+	if i < len(set) {
+		return true, set[i]
+	}
+	return false, nil
+}
+
+/// `true` if the account `index` is ready for reclaim.
+func (m *Module) CanReclaim(tryIndex AccountIndex) bool {
+	set := m.EnumSetStore.Get(tryIndex / EnumSetSize).(AccountIds)
+	i := int(tryIndex % EnumSetSize)
+	return i < len(set) && m.IsDeadAccount.IsDeadAccount(set[i])
+}
+
+/// Lookup an address to get an Id, if there's one there.
+func (m *Module) Lookup(a srprimitives.Address) (bool, srprimitives.AccountId) {
+	switch address := a.(type) {
+	case AddressId:
+		return true, address.Id
+	case AddressIndex:
+		return m.LookupIndex(AccountIndex(address))
+	}
+	return false, nil
+}
+
+func (m *Module) DecodeAddress(pd codec.Decoder) srprimitives.Address {
+	return DecodeAddress(pd, m.TypeParamsFactory.DecodeAccountId)
+}
+
+// Implements system.OnNewAccount: assigns an index to the new account.
+func (m *Module) OnNewAccount(who srprimitives.AccountId) {
+	nextSetIndex := m.NextEnumSetStore.Get().(AccountIndex)
+
+	if m.ResolveHint != nil {
+		ok, tryIndex := m.ResolveHint.ResolveHint(who)
+		// then check to see if this account id identifies a dead account index.
+		if ok && m.CanReclaim(tryIndex) {
+			// yup - this index refers to a dead account. can be reused.
+			setIndex := tryIndex / EnumSetSize
+			trySet := m.EnumSetStore.Get(setIndex).(AccountIds)
+			trySet[tryIndex%EnumSetSize] = who
+			m.EnumSetStore.Insert(setIndex, trySet)
+			return
+		}
+	}
+
+	// insert normally as a back up
+	setIndex := nextSetIndex
+	// defensive only: this loop should never iterate since we keep NextEnumSet up to date later.
+	var set AccountIds
+	for {
+		set = m.EnumSetStore.Get(setIndex).(AccountIds)
+		if len(set) < EnumSetSize {
+			break
+		}
+		setIndex++
+	}
+
+	index := setIndex*EnumSetSize + AccountIndex(len(set))
+
+	// update set.
+	set = append(set, who)
+
+	// keep NextEnumSet up to date
+	if len(set) == EnumSetSize {
+		m.NextEnumSetStore.Put(setIndex + 1)
+	}
+
+	// write set.
+	m.EnumSetStore.Insert(setIndex, set)
+
+	m.System.DepositEvent(m.OuterEvent(EventNewAccountIndex{who, index}))
+}
+
+// The indices module has no dispatchable calls
+func (m *Module) DecodeCall(pd codec.Decoder) srprimitives.Callable {
+	b := pd.DecodeByte()
+	panic(primitives.InvalidEnum(b, "indices Call"))
+}
+
+/// A new account index was assigned.
+///
+/// This event is not triggered when an existing index is reassigned
+/// to another `AccountId`.
+type EventNewAccountIndex struct {
+	Who   srprimitives.AccountId
+	Index AccountIndex
+}
+
+func (e EventNewAccountIndex) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(0)
+	e.Who.ParityEncode(pe)
+	e.Index.ParityEncode(pe)
+}
+
+func (m *Module) DecodeEvent(pd codec.Decoder) support.RawEvent {
+	b := pd.DecodeByte()
+	switch b {
+	case 0:
+		who := m.TypeParamsFactory.DecodeAccountId(pd)
+		return EventNewAccountIndex{who, AccountIndex(pd.DecodeUint32())}
+	}
+	panic(primitives.InvalidEnum(b, "indices Event"))
+}
+
+func (m *Module) StorageMetadata() metadata.StorageMetadata {
+	return metadata.StorageMetadata{"Indices", []metadata.StorageFunctionMetadata{
+		m.NextEnumSetStore.Metadata("T::AccountIndex", "The next free enumeration set."),
+		m.EnumSetStore.Metadata("T::AccountIndex", "Vec<T::AccountId>", "The enumeration sets."),
+	}}
+}
+
+func (m *Module) EventMetadata() []metadata.EventMetadata {
+	return []metadata.EventMetadata{
+		{"NewAccountIndex", []string{"AccountId", "AccountIndex"}, []string{
+			"A new account index was assigned.",
+			"",
+			"This event is not triggered when an existing index is reassigned",
+			"to another `AccountId`.",
+		}},
+	}
+}
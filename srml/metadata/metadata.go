@@ -1,6 +1,15 @@
 package metadata
 
-import "github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// The metadata is encoded as RuntimeMetadata (the unversioned layout) of srml-metadata.
+
+func encodeStrings(pe codec.Encoder, s []string) {
+	pe.EncodeCollection(len(s), func(i int) { pe.EncodeString(s[i]) })
+}
 
 /// All the metadata about a module.
 type ModuleMetadata struct {
@@ -73,6 +82,14 @@ type StorageFunctionTypePlain struct {
 
 func (_ StorageFunctionTypePlain) ImplementsStorageFunctionType() {}
 
+func (t StorageFunctionTypePlain) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{0, t}
+}
+
+func (t StorageFunctionTypePlain) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(t.V)
+}
+
 type StorageFunctionTypeMap struct {
 	Key   string
 	Value string
@@ -80,6 +97,15 @@ type StorageFunctionTypeMap struct {
 
 func (_ StorageFunctionTypeMap) ImplementsStorageFunctionType() {}
 
+func (t StorageFunctionTypeMap) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{1, t}
+}
+
+func (t StorageFunctionTypeMap) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(t.Key)
+	pe.EncodeString(t.Value)
+}
+
 /// A storage function modifier.
 type StorageFunctionModifier byte
 
@@ -90,8 +116,8 @@ const (
 
 /// All metadata about the outer dispatch.
 type OuterDispatchMetadata struct {
-	name  string
-	calls []OuterDispatchCall
+	Name  string
+	Calls []OuterDispatchCall
 }
 
 /// A Call from the outer dispatch.
@@ -115,3 +141,77 @@ type RuntimeMetadata struct {
 	Modules       []RuntimeModuleMetadata
 	OuterDispatch OuterDispatchMetadata
 }
+
+func (m ModuleMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	m.Call.ParityEncode(pe)
+}
+
+func (m CallMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	pe.EncodeCollection(len(m.Functions), func(i int) { m.Functions[i].ParityEncode(pe) })
+}
+
+func (m FunctionMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint16(m.Id)
+	pe.EncodeString(m.Name)
+	pe.EncodeCollection(len(m.Arguments), func(i int) { m.Arguments[i].ParityEncode(pe) })
+	encodeStrings(pe, m.Documentation)
+}
+
+func (m FunctionArgumentMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	pe.EncodeString(m.Ty)
+}
+
+func (m EventData) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	pe.EncodeCollection(len(m.Metadata), func(i int) { m.Metadata[i].ParityEncode(pe) })
+}
+
+func (m OuterEventMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	pe.EncodeCollection(len(m.Events), func(i int) { m.Events[i].ParityEncode(pe) })
+}
+
+func (m EventMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	encodeStrings(pe, m.Arguments)
+	encodeStrings(pe, m.Documentation)
+}
+
+func (m StorageMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Prefix)
+	pe.EncodeCollection(len(m.Functions), func(i int) { m.Functions[i].ParityEncode(pe) })
+}
+
+func (m StorageFunctionMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	pe.EncodeByte(byte(m.Modifier))
+	m.Ty.EncodeableEnum().ParityEncode(pe)
+	pe.EncodeByteSlice(m.Default)
+	encodeStrings(pe, m.Documentation)
+}
+
+func (m OuterDispatchMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	pe.EncodeCollection(len(m.Calls), func(i int) { m.Calls[i].ParityEncode(pe) })
+}
+
+func (m OuterDispatchCall) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Name)
+	pe.EncodeString(m.Prefix)
+	pe.EncodeUint16(m.Index)
+}
+
+func (m RuntimeModuleMetadata) ParityEncode(pe codec.Encoder) {
+	pe.EncodeString(m.Prefix)
+	m.Module.ParityEncode(pe)
+	pe.EncodeOption(m.HasStorage, m.Storage)
+}
+
+func (m RuntimeMetadata) ParityEncode(pe codec.Encoder) {
+	m.OuterEvent.ParityEncode(pe)
+	pe.EncodeCollection(len(m.Modules), func(i int) { m.Modules[i].ParityEncode(pe) })
+	m.OuterDispatch.ParityEncode(pe)
+}
@@ -0,0 +1,212 @@
+package sudo
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/storage"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/system"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// The sudo module allows for a single account (called the "sudo key")
+// to execute dispatchable functions that require a `Root` call
+// or designate a new account to replace them as the sudo key.
+
+type Module struct {
+	support.BaseModule
+	TypeParamsFactory support.TypeParamsFactory
+	System            *system.Module
+	/// Resolves the new key passed to set_key, usually indices.Module.
+	Lookup srprimitives.StaticLookup
+	/// Decodes the calls dispatched by sudo, i.e. the runtime's "Call" enum.
+	Proposal support.CallDecoder
+
+	/// The `AccountId` of the sudo key.
+	KeyStore storage.SimpleStorageValue
+}
+
+func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
+	m.TypeParamsFactory = r
+
+	m.KeyStore = storage.SimpleStorageValue{
+		[]byte("Sudo Key"),
+		"",
+		func() storage.StoredValue { return nil },
+		func(pd codec.Decoder) storage.StoredValue { return m.TypeParamsFactory.DecodeAccountId(pd) },
+	}
+}
+
+/// Initial state of the module
+type GenesisConfig struct {
+	Key srprimitives.AccountId
+}
+
+/// Writes the initial state of the module into the storage.
+func (m *Module) BuildStorage(config GenesisConfig) {
+	m.KeyStore.Put(config.Key)
+}
+
+/// The `AccountId` of the sudo key, nil if none is set.
+func (m *Module) Key() srprimitives.AccountId {
+	key := m.KeyStore.Get()
+	if key == nil {
+		return nil
+	}
+	return key.(srprimitives.AccountId)
+}
+
+func (m *Module) isKey(who srprimitives.AccountId) bool {
+	key := m.Key()
+	return key != nil && bytes.Equal(codec.ToBytes(key), codec.ToBytes(who))
+}
+
+// Method IDs
+const (
+	SudoId   byte = 0
+	SetKeyId byte = 1
+)
+
+/// Authenticates the sudo key and dispatches a function call with `Root` origin.
+///
+/// The dispatch origin for this call must be _Signed_.
+type SudoCall struct {
+	m        *Module
+	Proposal srprimitives.Callable
+}
+
+func (m *Module) SudoCall(proposal srprimitives.Callable) SudoCall {
+	return SudoCall{m, proposal}
+}
+
+func (c SudoCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{SudoId, c.Proposal.EncodeableEnum()}
+}
+
+func (c SudoCall) Dispatch(o srprimitives.Origin) error {
+	sender, err := system.EnsureSigned(o)
+	if err != nil {
+		return err
+	}
+	if !c.m.isKey(sender) {
+		return errors.New("only the current sudo key can sudo")
+	}
+
+	ok := c.Proposal.Dispatch(system.RawOriginRoot{}) == nil
+	c.m.System.DepositEvent(c.m.OuterEvent(EventSudid(ok)))
+	return nil
+}
+
+/// Authenticates the current sudo key and sets the given AccountId (`new`) as the new sudo key.
+///
+/// The dispatch origin for this call must be _Signed_.
+type SetKeyCall struct {
+	m   *Module
+	New srprimitives.Address
+}
+
+func (m *Module) SetKeyCall(new srprimitives.Address) SetKeyCall {
+	return SetKeyCall{m, new}
+}
+
+func (c SetKeyCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{SetKeyId, c.New}
+}
+
+func (c SetKeyCall) Dispatch(o srprimitives.Origin) error {
+	sender, err := system.EnsureSigned(o)
+	if err != nil {
+		return err
+	}
+	if !c.m.isKey(sender) {
+		return errors.New("only the current sudo key can change the sudo key")
+	}
+	ok, new := c.m.Lookup.Lookup(c.New)
+	if !ok {
+		return errors.New("invalid account index")
+	}
+
+	c.m.System.DepositEvent(c.m.OuterEvent(EventKeyChanged{c.m.Key()}))
+	c.m.KeyStore.Put(new)
+	return nil
+}
+
+func (m *Module) DecodeCall(pd codec.Decoder) srprimitives.Callable {
+	b := pd.DecodeByte()
+	switch b {
+	case SudoId:
+		return SudoCall{m, m.Proposal.DecodeCall(pd)}
+	case SetKeyId:
+		return SetKeyCall{m, m.Lookup.DecodeAddress(pd)}
+	}
+	panic(primitives.InvalidEnum(b, "sudo Call"))
+}
+
+func (m *Module) CallableBelongsToThisModule(c srprimitives.Callable) bool {
+	switch c.(type) {
+	case SudoCall, SetKeyCall:
+		return true
+	}
+	return false
+}
+
+/// A sudo just took place.
+type EventSudid bool
+
+func (e EventSudid) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(0)
+	pe.EncodeBool(bool(e))
+}
+
+/// The sudoer just switched identity; the old key is supplied.
+type EventKeyChanged struct {
+	Old srprimitives.AccountId
+}
+
+func (e EventKeyChanged) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(1)
+	e.Old.ParityEncode(pe)
+}
+
+func (m *Module) DecodeEvent(pd codec.Decoder) support.RawEvent {
+	b := pd.DecodeByte()
+	switch b {
+	case 0:
+		return EventSudid(pd.DecodeBool())
+	case 1:
+		return EventKeyChanged{m.TypeParamsFactory.DecodeAccountId(pd)}
+	}
+	panic(primitives.InvalidEnum(b, "sudo Event"))
+}
+
+func (m *Module) CallMetadata() []metadata.FunctionMetadata {
+	return []metadata.FunctionMetadata{
+		{uint16(SudoId), "sudo", []metadata.FunctionArgumentMetadata{{"proposal", "Box<T::Proposal>"}}, []string{
+			"Authenticates the sudo key and dispatches a function call with `Root` origin.",
+			"",
+			"The dispatch origin for this call must be _Signed_.",
+		}},
+		{uint16(SetKeyId), "set_key", []metadata.FunctionArgumentMetadata{{"new", "<T::Lookup as StaticLookup>::Source"}}, []string{
+			"Authenticates the current sudo key and sets the given AccountId (`new`) as the new sudo key.",
+			"",
+			"The dispatch origin for this call must be _Signed_.",
+		}},
+	}
+}
+
+func (m *Module) StorageMetadata() metadata.StorageMetadata {
+	return metadata.StorageMetadata{"Sudo", []metadata.StorageFunctionMetadata{
+		m.KeyStore.Metadata("T::AccountId", "The `AccountId` of the sudo key."),
+	}}
+}
+
+func (m *Module) EventMetadata() []metadata.EventMetadata {
+	return []metadata.EventMetadata{
+		{"Sudid", []string{"bool"}, []string{"A sudo just took place."}},
+		{"KeyChanged", []string{"AccountId"}, []string{"The sudoer just switched identity; the old key is supplied."}},
+	}
+}
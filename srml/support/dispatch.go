@@ -9,12 +9,24 @@ type BaseModule struct {
 
 	// Order is important, because it determines the encoding of the "module"
 	methods []srprimitives.Callable
+
+	// Index of the module in the runtime's "Event" enum
+	eventIndex byte
+
+	// Index of the module in the runtime's "Log"
+	logIndex byte
 }
 
 type Module interface {
 	InitForRuntime(TypeParamsFactory)
 }
 
+// Modules registered with a Call implement this to decode their calls.
+// Similar to "Call" enum generated by decl_module! in Rust.
+type CallDecoder interface {
+	DecodeCall(pd codec.Decoder) srprimitives.Callable
+}
+
 func (m *BaseModule) AddMethod(c srprimitives.Callable) {
 	m.methods = append(m.methods, c)
 }
@@ -39,8 +51,11 @@ type TypeParamsFactory interface {
 	BlockNumber(uint64) srprimitives.BlockNumber
 	DecodeDigestItem(pd codec.Decoder) srprimitives.DigestItem
 	ZeroIndex() srprimitives.Index
+	DecodeAccountId(pd codec.Decoder) srprimitives.AccountId
 	DecodeEvent(pd codec.Decoder) Event
 	DefaultContext() interface{}
+	/// The hashing of the runtime, used for the block hashes and the trie roots.
+	Hasher() srprimitives.Hasher
 }
 
 // Since implementations of type parameters might differ from runtime to runtime,
@@ -9,3 +9,32 @@ type Event RawEvent
 type RawEvent interface {
 	codec.Encodeable
 }
+
+// Corresponds to "Event" enum generated by construct_runtime! in Rust:
+// index of the module (among the modules with events), followed by the module's event
+type OuterEvent struct {
+	ModuleIndex byte
+	Event       RawEvent
+}
+
+func (e OuterEvent) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(e.ModuleIndex)
+	e.Event.ParityEncode(pe)
+}
+
+// Modules registered with an Event implement this to decode their events.
+// Similar to "RawEvent" enum generated by decl_event! in Rust.
+type EventDecoder interface {
+	DecodeEvent(pd codec.Decoder) RawEvent
+	SetEventIndex(i byte)
+}
+
+// Set by the runtime when the module is registered
+func (m *BaseModule) SetEventIndex(i byte) {
+	m.eventIndex = i
+}
+
+// Wraps the module's event into the runtime's "Event" enum
+func (m *BaseModule) OuterEvent(e RawEvent) Event {
+	return OuterEvent{m.eventIndex, e}
+}
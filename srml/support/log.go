@@ -0,0 +1,48 @@
+package support
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// Corresponds to "Log" generated by construct_runtime! (impl_outer_log!) in Rust.
+// The system digest items (e.g. srprimitives.AuthoritiesChange) are deposited as they are,
+// while the other logs of the modules are wrapped into an "Other" digest item, containing
+// the index of the module (among the modules with a Log) followed by the module's log.
+
+type RawLog interface {
+	codec.Encodeable
+}
+
+type OuterLog struct {
+	ModuleIndex byte
+	Log         RawLog
+}
+
+func (l OuterLog) DigestItemType() srprimitives.DigestItemType {
+	return srprimitives.DtOther
+}
+
+func (l OuterLog) ParityEncode(pe codec.Encoder) {
+	srprimitives.OtherDigestItem(codec.ToBytesCustom(func(pe codec.Encoder) {
+		pe.EncodeByte(l.ModuleIndex)
+		l.Log.ParityEncode(pe)
+	})).ParityEncode(pe)
+}
+
+// Modules registered with a Log of their own (not only system digest items)
+// implement this to decode their logs. Similar to "RawLog" enum in Rust modules.
+type LogDecoder interface {
+	DecodeLog(pd codec.Decoder) RawLog
+	SetLogIndex(i byte)
+}
+
+// Set by the runtime when the module is registered
+func (m *BaseModule) SetLogIndex(i byte) {
+	m.logIndex = i
+}
+
+// Wraps the module's log into the runtime's "Log", to be deposited with system.Module.DepositLog
+func (m *BaseModule) OuterLog(l RawLog) srprimitives.DigestItem {
+	return OuterLog{m.logIndex, l}
+}
@@ -0,0 +1,24 @@
+package support
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
+)
+
+// Modules implement these to describe themselves in the runtime metadata,
+// like the metadata functions generated by decl_module!, decl_storage! and decl_event! in Rust.
+// The runtime asks only the modules registered with the corresponding flags.
+
+type CallMetadataProvider interface {
+	/// The dispatchable functions, in the order of their ids.
+	CallMetadata() []metadata.FunctionMetadata
+}
+
+type StorageMetadataProvider interface {
+	/// The storage items; the prefix is the module's storage prefix, e.g. "Balances".
+	StorageMetadata() metadata.StorageMetadata
+}
+
+type EventMetadataProvider interface {
+	/// The events, in the order of their indices.
+	EventMetadata() []metadata.EventMetadata
+}
@@ -0,0 +1,170 @@
+package migration
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srio"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/storage"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// Storage migrations evolve the layout of the storage of the modules between runtime versions.
+//
+// Each module has a storage version, stored on chain under "<Module> StorageVersion"
+// (0 if none is stored). A migration step brings the storage of a module from the
+// previous version to the step's version. After a runtime upgrade (see
+// system.Module.RuntimeUpgraded), the pending steps of each module are executed
+// in order, and the storage version is bumped after each step, so every step
+// runs exactly once.
+//
+// Since the keys of the storage are hashed, the values of a map can not be enumerated:
+// migrations of maps have to know the keys, e.g. from another storage item.
+
+/// A migration step, bringing the storage of a module to Version
+type Step struct {
+	Version     uint32
+	Description string
+	Migrate     func()
+}
+
+/// The migration steps of a module, in the ascending order of the versions
+type ModuleMigrations struct {
+	Module string
+	Steps  []Step
+}
+
+func (m *ModuleMigrations) storageVersionStore() storage.SimpleStorageValue {
+	return storage.SimpleStorageValue{
+		[]byte(m.Module + " StorageVersion"),
+		"",
+		func() storage.StoredValue { return gohelpers.Uint32(0) },
+		func(pd codec.Decoder) storage.StoredValue {
+			return gohelpers.Uint32(pd.DecodeUint32())
+		},
+	}
+}
+
+/// The storage version of the module, 0 if none is stored
+func (m *ModuleMigrations) StorageVersion() uint32 {
+	store := m.storageVersionStore()
+	return uint32(store.Get().(gohelpers.Uint32))
+}
+
+func (m *ModuleMigrations) setStorageVersion(version uint32) {
+	store := m.storageVersionStore()
+	store.Put(gohelpers.Uint32(version))
+}
+
+/// The version of the storage layout expected by the runtime
+func (m *ModuleMigrations) LatestVersion() uint32 {
+	if len(m.Steps) == 0 {
+		return 0
+	}
+	return m.Steps[len(m.Steps)-1].Version
+}
+
+/// The migrations of the modules of a runtime
+type Registry struct {
+	Modules []ModuleMigrations
+}
+
+/// Registers the migration steps of a module, which must have ascending versions
+func (r *Registry) Register(module string, steps ...Step) {
+	for i := range steps {
+		if steps[i].Version == 0 || (i > 0 && steps[i].Version <= steps[i-1].Version) {
+			panic("Migration steps of " + module + " must have ascending versions above 0")
+		}
+	}
+	for i := range r.Modules {
+		if r.Modules[i].Module == module {
+			panic("Migrations of " + module + " are already registered")
+		}
+	}
+	r.Modules = append(r.Modules, ModuleMigrations{module, steps})
+}
+
+/// A step executed by Migrate
+type AppliedStep struct {
+	Module      string
+	Version     uint32
+	Description string
+}
+
+/// Executes the pending migration steps of all the modules, in the order of registration
+func (r *Registry) Migrate() []AppliedStep {
+	return r.migrate((*ModuleMigrations).StorageVersion, (*ModuleMigrations).setStorageVersion)
+}
+
+// Migrate, with the storage versions read and written by the given functions
+func (r *Registry) migrate(storageVersion func(*ModuleMigrations) uint32, setStorageVersion func(*ModuleMigrations, uint32)) []AppliedStep {
+	var applied []AppliedStep
+	for i := range r.Modules {
+		m := &r.Modules[i]
+		current := storageVersion(m)
+		for _, step := range m.Steps {
+			if step.Version <= current {
+				continue
+			}
+			step.Migrate()
+			setStorageVersion(m, step.Version)
+			applied = append(applied, AppliedStep{m.Module, step.Version, step.Description})
+		}
+	}
+	return applied
+}
+
+/// Executes the pending migration steps without writing to the storage,
+/// and returns the steps along with the storage changes they would make.
+func (r *Registry) DryRun() ([]AppliedStep, *srio.Overlay) {
+	var applied []AppliedStep
+	changes := srio.DryRun(func() { applied = r.Migrate() })
+	return applied, changes
+}
+
+/// Writes the latest storage versions at genesis, as the initial storage is in the latest layout.
+func (r *Registry) BuildStorage() {
+	for i := range r.Modules {
+		r.Modules[i].setStorageVersion(r.Modules[i].LatestVersion())
+	}
+}
+
+// Helpers for the typical steps
+
+/// Re-encodes the value of a storage item, possibly moving it to a new key.
+/// transform returns nil to remove the value.
+func TranslateValue(old *storage.SimpleStorageValue, new *storage.SimpleStorageValue, transform func(storage.StoredValue) codec.Encodeable) {
+	ok, _ := storage.Get(old.KeyString)
+	if !ok {
+		return
+	}
+	value := transform(old.Take())
+	if value != nil {
+		new.Put(value)
+	}
+}
+
+/// Re-encodes the values of a map under the given keys, possibly moving them to a new map.
+/// transform returns nil to remove the value.
+func TranslateMapValues(old *storage.MapStorageValue, new *storage.MapStorageValue, keys []codec.Encodeable, transform func(storage.StoredValue) codec.Encodeable) {
+	for _, key := range keys {
+		if !old.Exists(key) {
+			continue
+		}
+		value := transform(old.Take(key))
+		if value != nil {
+			new.Insert(key, value)
+		}
+	}
+}
+
+/// A change of the key of a value in a map
+type KeyChange struct {
+	Old codec.Encodeable
+	New codec.Encodeable
+}
+
+/// Moves the values of a map to new keys, e.g. after a change of the key type.
+func RekeyMap(m *storage.MapStorageValue, changes []KeyChange) {
+	for _, c := range changes {
+		m.Rekey(c.Old, c.New)
+	}
+}
@@ -0,0 +1,103 @@
+package migration
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The storage is not available natively: the storage versions are kept in a slice
+type testVersions struct {
+	modules  []string
+	versions []uint32
+	log      *[]string
+}
+
+func (v *testVersions) index(m *ModuleMigrations) int {
+	for i := range v.modules {
+		if v.modules[i] == m.Module {
+			return i
+		}
+	}
+	v.modules = append(v.modules, m.Module)
+	v.versions = append(v.versions, 0)
+	return len(v.modules) - 1
+}
+
+func (v *testVersions) get(m *ModuleMigrations) uint32 {
+	return v.versions[v.index(m)]
+}
+
+func (v *testVersions) set(m *ModuleMigrations, version uint32) {
+	v.versions[v.index(m)] = version
+	*v.log = append(*v.log, m.Module+"="+strconv.Itoa(int(version)))
+}
+
+func logStep(log *[]string, name string) Step {
+	version, _ := strconv.Atoi(name[len(name)-1:])
+	return Step{uint32(version), name, func() { *log = append(*log, name) }}
+}
+
+func TestMigrateOrder(t *testing.T) {
+	var log []string
+	r := Registry{}
+	r.Register("Balances", logStep(&log, "Balances1"), logStep(&log, "Balances2"), logStep(&log, "Balances3"))
+	r.Register("Indices", logStep(&log, "Indices1"), logStep(&log, "Indices2"))
+	r.Register("Sudo")
+	versions := &testVersions{[]string{"Balances"}, []uint32{1}, &log}
+
+	applied := r.migrate(versions.get, versions.set)
+	// The modules in the order of registration, the steps in order, each version bumped after its step
+	expected := "Balances2 Balances=2 Balances3 Balances=3 Indices1 Indices=1 Indices2 Indices=2"
+	if strings.Join(log, " ") != expected {
+		t.Errorf("got %v, expected %s", log, expected)
+	}
+	if len(applied) != 4 || applied[0] != (AppliedStep{"Balances", 2, "Balances2"}) || applied[3] != (AppliedStep{"Indices", 2, "Indices2"}) {
+		t.Errorf("applied %v", applied)
+	}
+
+	// Every step runs once
+	log = nil
+	if applied := r.migrate(versions.get, versions.set); len(applied) != 0 || len(log) != 0 {
+		t.Errorf("the second migration applied %v", applied)
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	var log []string
+	r := Registry{}
+	r.Register("Balances", logStep(&log, "Balances1"), logStep(&log, "Balances3"))
+	r.Register("Sudo")
+	if r.Modules[0].LatestVersion() != 3 || r.Modules[1].LatestVersion() != 0 {
+		t.Error("LatestVersion")
+	}
+}
+
+func registerPanics(r *Registry, module string, steps ...Step) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	r.Register(module, steps...)
+	return false
+}
+
+func TestRegisterChecksTheSteps(t *testing.T) {
+	var log []string
+	r := Registry{}
+	if !registerPanics(&r, "Balances", logStep(&log, "Balances2"), logStep(&log, "Balances1")) {
+		t.Error("descending versions should panic")
+	}
+	if !registerPanics(&r, "Balances", logStep(&log, "Balances1"), logStep(&log, "Balances1")) {
+		t.Error("repeated versions should panic")
+	}
+	if !registerPanics(&r, "Balances", logStep(&log, "Balances0")) {
+		t.Error("the version 0 should panic")
+	}
+	if registerPanics(&r, "Balances", logStep(&log, "Balances1")) {
+		t.Error("ascending versions should register")
+	}
+	if !registerPanics(&r, "Balances", logStep(&log, "Balances2")) {
+		t.Error("registering a module twice should panic")
+	}
+	if len(r.Modules) != 1 {
+		t.Errorf("registered %d modules", len(r.Modules))
+	}
+}
@@ -1,11 +1,16 @@
 package runtime
 
 import (
+	"bytes"
+	"strings"
+
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
 	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
 	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/migration"
 	"github.com/Joystream/tinygo-wasm-substrate/srml/system"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
 )
 
 // While in Rust implementation of SRML this is an enum generated by a
@@ -14,10 +19,15 @@ import (
 // Runtime is the object encapsulating your whole application.
 // It is configured by the specifications of type parameters and plugged in modules.
 type Runtime struct {
-	Modules         []ModuleAndFlags
-	ModulesWithCall []support.Module
-	System          system.Module
-	TypeParams      support.TypeParamsFactory
+	Modules          []ModuleAndFlags
+	ModulesWithCall  []support.Module
+	ModulesWithEvent []support.Module
+	ModulesWithLog   []support.Module
+	System           system.Module
+	TypeParams       support.TypeParamsFactory
+	/// Storage migrations of the modules, executed at the start of the first block
+	/// after a runtime upgrade, before the OnInitialise hooks of the modules.
+	Migrations *migration.Registry
 }
 
 // Corresponds to "Call" enum generated for Rust runtime
@@ -30,17 +40,48 @@ func (r RuntimeCall) Dispatch(o srprimitives.Origin) error {
 	return r.moduleCall.Dispatch(o)
 }
 
+func (r RuntimeCall) Weight(encodedLen uintptr) srprimitives.Weight {
+	return srprimitives.WeightOf(r.moduleCall, encodedLen)
+}
+
 func (r *Runtime) ModuleForCall(call RuntimeCall) support.Module {
 	return r.ModulesWithCall[call.moduleIndex]
 }
 
+// The call of the module, without the module index
+func (r RuntimeCall) ModuleCall() srprimitives.Callable {
+	return r.moduleCall
+}
+
+// Wraps a call of a module registered with a Call into the runtime's "Call" enum
+func (r *Runtime) WrapCall(m support.Module, c srprimitives.Callable) RuntimeCall {
+	for i, mc := range r.ModulesWithCall {
+		if mc == m {
+			return RuntimeCall{byte(i), c}
+		}
+	}
+	panic("Module is not registered with a Call")
+}
+
 func (c RuntimeCall) EncodeableEnum() primitives.EncodeableEnum {
 	return primitives.EncodeableEnum{c.moduleIndex, c.moduleCall.EncodeableEnum()}
 }
 
+// Decodes the "Call" enum: index of the module, followed by the module's call
+func (r *Runtime) DecodeCall(pd codec.Decoder) srprimitives.Callable {
+	i := pd.DecodeByte()
+	if int(i) >= len(r.ModulesWithCall) {
+		panic(primitives.InvalidEnum(i, "Call"))
+	}
+	return RuntimeCall{i, r.ModulesWithCall[i].(support.CallDecoder).DecodeCall(pd)}
+}
+
 // Determines what types we import from the module.
 // Similar to module lines in construct_module! macro.
 type ModuleFlags struct {
+	/// The name of the module in construct_runtime!, e.g. "Balances", used in the metadata.
+	/// The module's crate (the prefix in the metadata) is assumed to be the name in lowercase.
+	Name     string
 	Module   bool
 	Call     bool
 	Storage  bool
@@ -65,18 +106,135 @@ type ModuleAndFlags struct {
 	Flags  ModuleFlags
 }
 
-func RegisterModule(r Runtime, m support.Module, f ModuleFlags) {
+func RegisterModule(r *Runtime, m support.Module, f ModuleFlags) {
 	m.InitForRuntime(r.TypeParams)
 	r.Modules = append(r.Modules, ModuleAndFlags{m, f})
 	if f.Call {
 		r.ModulesWithCall = append(r.ModulesWithCall, m)
 	}
+	if f.Event {
+		m.(support.EventDecoder).SetEventIndex(byte(len(r.ModulesWithEvent)))
+		r.ModulesWithEvent = append(r.ModulesWithEvent, m)
+	}
+	if f.Log != "" {
+		ld, ok := m.(support.LogDecoder)
+		if ok {
+			ld.SetLogIndex(byte(len(r.ModulesWithLog)))
+		}
+		r.ModulesWithLog = append(r.ModulesWithLog, m)
+	}
 	sm, ok := m.(*system.Module)
 	if ok {
 		r.System = *sm
 	}
 }
 
+// Decodes the "Event" enum: index of the module, followed by the module's event
+func (r *Runtime) DecodeEvent(pd codec.Decoder) support.Event {
+	i := pd.DecodeByte()
+	if int(i) >= len(r.ModulesWithEvent) {
+		panic(primitives.InvalidEnum(i, "Event"))
+	}
+	return support.OuterEvent{i, r.ModulesWithEvent[i].(support.EventDecoder).DecodeEvent(pd)}
+}
+
+// Decodes the "Log": a digest item, where the "Other" items are the logs of the modules,
+// see support.OuterLog
+func (r *Runtime) DecodeLog(pd codec.Decoder, aidFactory func() srprimitives.AuthorityId) srprimitives.DigestItem {
+	item := srprimitives.DecodeDigestItem(pd, aidFactory)
+	other, ok := item.(srprimitives.OtherDigestItem)
+	if !ok {
+		return item
+	}
+	opd := codec.Decoder{bytes.NewBuffer(other)}
+	i := opd.DecodeByte()
+	if int(i) >= len(r.ModulesWithLog) {
+		panic(primitives.InvalidEnum(i, "Log"))
+	}
+	ld, ok := r.ModulesWithLog[i].(support.LogDecoder)
+	if !ok {
+		// The module has only system digest items
+		panic(primitives.InvalidEnum(i, "Log"))
+	}
+	return support.OuterLog{i, ld.DecodeLog(opd)}
+}
+
+// Runtime implements the per-block hooks as "AllModules" does in Rust:
+// the hook is called on every registered module that implements it,
+// in the order of registration.
+
+func (r *Runtime) OnInitialise(n srprimitives.BlockNumber) {
+	if r.Migrations != nil {
+		upgraded, _ := r.System.RuntimeUpgraded()
+		if upgraded {
+			r.Migrations.Migrate()
+		}
+	}
+	for _, m := range r.Modules {
+		hook, ok := m.Module.(srprimitives.OnInitialise)
+		if ok {
+			hook.OnInitialise(n)
+		}
+	}
+}
+
+func (r *Runtime) OnFinalise(n srprimitives.BlockNumber) {
+	for _, m := range r.Modules {
+		hook, ok := m.Module.(srprimitives.OnFinalise)
+		if ok {
+			hook.OnFinalise(n)
+		}
+	}
+}
+
+func (r *Runtime) GenerateExtrinsics(n srprimitives.BlockNumber) {
+	for _, m := range r.Modules {
+		hook, ok := m.Module.(srprimitives.OffchainWorker)
+		if ok {
+			hook.GenerateExtrinsics(n)
+		}
+	}
+}
+
+/// The metadata of the runtime, assembled from the metadata of the registered modules.
 func (r Runtime) GetMetadata() metadata.RuntimeMetadata {
-	return metadata.RuntimeMetadata{}
+	res := metadata.RuntimeMetadata{
+		OuterEvent:    metadata.OuterEventMetadata{Name: "Event"},
+		OuterDispatch: metadata.OuterDispatchMetadata{Name: "Call"},
+	}
+	for _, m := range r.Modules {
+		prefix := strings.ToLower(m.Flags.Name)
+		if m.Flags.Event {
+			var events []metadata.EventMetadata
+			ep, ok := m.Module.(support.EventMetadataProvider)
+			if ok {
+				events = ep.EventMetadata()
+			}
+			res.OuterEvent.Events = append(res.OuterEvent.Events, metadata.EventData{prefix, events})
+		}
+		if !m.Flags.Module {
+			continue
+		}
+		mm := metadata.RuntimeModuleMetadata{
+			Prefix: prefix,
+			Module: metadata.ModuleMetadata{"Module", metadata.CallMetadata{Name: "Call"}},
+		}
+		if m.Flags.Call {
+			cp, ok := m.Module.(support.CallMetadataProvider)
+			if ok {
+				mm.Module.Call.Functions = cp.CallMetadata()
+			}
+			res.OuterDispatch.Calls = append(res.OuterDispatch.Calls,
+				metadata.OuterDispatchCall{m.Flags.Name, prefix, uint16(len(res.OuterDispatch.Calls))})
+		}
+		if m.Flags.Storage {
+			sp, ok := m.Module.(support.StorageMetadataProvider)
+			if ok {
+				mm.HasStorage = true
+				mm.Storage = sp.StorageMetadata()
+			}
+		}
+		res.Modules = append(res.Modules, mm)
+	}
+	return res
 }
@@ -0,0 +1,116 @@
+package runtime_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/runtime"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// A module depositing only system digest items, as System and Consensus do
+type systemLogModule struct {
+	support.BaseModule
+}
+
+func (m *systemLogModule) InitForRuntime(support.TypeParamsFactory) {}
+
+// A module with a log of its own, as "enum RawLog { Checkpoint(u32) }" in Rust
+type exampleModule struct {
+	support.BaseModule
+}
+
+func (m *exampleModule) InitForRuntime(support.TypeParamsFactory) {}
+
+type checkpoint uint32
+
+func (c checkpoint) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(0)
+	pe.EncodeUint32(uint32(c))
+}
+
+func (m *exampleModule) DecodeLog(pd codec.Decoder) support.RawLog {
+	if v := pd.DecodeByte(); v != 0 {
+		panic(primitives.InvalidEnum(v, "RawLog"))
+	}
+	return checkpoint(pd.DecodeUint32())
+}
+
+func authorityIdFactory() srprimitives.AuthorityId {
+	return &primitives.Ed25519AuthorityId{}
+}
+
+// As construct_runtime! with System: Log(ChangesTrieRoot), Consensus: Log(AuthoritiesChange)
+// and Example: Log()
+func logRuntime() (*runtime.Runtime, *exampleModule) {
+	r := &runtime.Runtime{}
+	example := &exampleModule{}
+	runtime.RegisterModule(r, &systemLogModule{}, runtime.ModuleFlags{Name: "System", Log: "ChangesTrieRoot"})
+	runtime.RegisterModule(r, &systemLogModule{}, runtime.ModuleFlags{Name: "Consensus", Log: "AuthoritiesChange"})
+	runtime.RegisterModule(r, example, runtime.ModuleFlags{Name: "Example", Log: "Log"})
+	return r, example
+}
+
+func decodeLog(r *runtime.Runtime, encoded []byte) srprimitives.DigestItem {
+	return r.DecodeLog(codec.Decoder{bytes.NewBuffer(encoded)}, authorityIdFactory)
+}
+
+func TestModuleLog(t *testing.T) {
+	r, example := logRuntime()
+	log := example.OuterLog(checkpoint(1234567))
+	// The digest item of Log(InternalLog::example(RawLog::Checkpoint(1234567))),
+	// computed with the Rust code of impl_outer_log! and of DigestItem
+	const expected = "0018020087d61200"
+	encoded := codec.ToBytes(log)
+	if e := hex.EncodeToString(encoded); e != expected {
+		t.Errorf("encoded as %s, expected %s", e, expected)
+	}
+	decoded := decodeLog(r, encoded)
+	if !reflect.DeepEqual(decoded, support.OuterLog{2, checkpoint(1234567)}) {
+		t.Errorf("decoded as %#v", decoded)
+	}
+	if decoded.DigestItemType() != srprimitives.DtOther {
+		t.Errorf("digest item type %d", decoded.DigestItemType())
+	}
+}
+
+func TestSystemItemsAreNotWrapped(t *testing.T) {
+	r, _ := logRuntime()
+	var root primitives.H256
+	root[0] = 7
+	items := []srprimitives.DigestItem{
+		srprimitives.ChangesTrieRoot(root),
+		srprimitives.Seal{42, srprimitives.Signature{1, 2, 3}},
+		srprimitives.AuthoritiesChange{&primitives.Ed25519AuthorityId{1}},
+	}
+	for _, item := range items {
+		encoded := codec.ToBytes(item)
+		decoded := decodeLog(r, encoded)
+		if !reflect.DeepEqual(decoded, item) {
+			t.Errorf("%T decoded as %#v", item, decoded)
+		}
+		if !bytes.Equal(codec.ToBytes(decoded), encoded) {
+			t.Errorf("%T re-encoded differently", item)
+		}
+	}
+}
+
+func TestOtherOfModulesWithoutLog(t *testing.T) {
+	r, _ := logRuntime()
+	// Index 0 is System, which has only system digest items; index 3 is not registered
+	for _, i := range []byte{0, 3} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("module %d: expected a panic", i)
+				}
+			}()
+			decodeLog(r, codec.ToBytes(srprimitives.OtherDigestItem{i, 0}))
+		}()
+	}
+}
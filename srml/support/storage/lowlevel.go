@@ -38,3 +38,15 @@ func GetUint64Or(key []byte, deflt uint64) uint64 {
 func Kill(key []byte) {
 	srio.UnhashedKill(hashStorageKey(key))
 }
+
+/// Move the value under a key to another key, without decoding it.
+/// Returns false if there is no value under the old key.
+func MoveValue(oldKey []byte, newKey []byte) bool {
+	ok, value := Get(oldKey)
+	if !ok {
+		return false
+	}
+	Kill(oldKey)
+	Put(newKey, value)
+	return true
+}
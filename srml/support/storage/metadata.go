@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"bytes"
+
+	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// The metadata of the storage items, as generated by decl_storage! in Rust.
+// The types are given as their names in the Rust runtime, e.g. "T::Balance".
+
+/// The metadata of the value, whose type is ty.
+func (s *SimpleStorageValue) Metadata(ty string, docs ...string) metadata.StorageFunctionMetadata {
+	return storageFunctionMetadata(s.KeyString, s.DefaultValueFactory, metadata.StorageFunctionTypePlain{ty}, docs)
+}
+
+/// The metadata of the map from key to value types.
+func (s *MapStorageValue) Metadata(key string, value string, docs ...string) metadata.StorageFunctionMetadata {
+	return storageFunctionMetadata(s.PrefixString, s.DefaultValueFactory, metadata.StorageFunctionTypeMap{key, value}, docs)
+}
+
+// The name is the part of the key after the module's prefix, e.g. "FreeBalance" in "Balances FreeBalance"
+func storageFunctionMetadata(key []byte, defaultValue func() StoredValue, ty metadata.StorageFunctionType, docs []string) metadata.StorageFunctionMetadata {
+	name := key[bytes.LastIndexByte(key, ' ')+1:]
+	value := defaultValue()
+	if value == nil {
+		// The encoded None of an Option
+		return metadata.StorageFunctionMetadata{string(name), metadata.StorageFunctionModifierOptional, ty, []byte{0}, docs}
+	}
+	var deflt []byte
+	switch v := value.(type) {
+	case codec.Encodeable:
+		deflt = codec.ToBytes(v)
+	case []byte:
+		deflt = codec.ToBytesCustom(func(pe codec.Encoder) { pe.EncodeByteSlice(v) })
+	}
+	return metadata.StorageFunctionMetadata{string(name), metadata.StorageFunctionModifierDefault, ty, deflt, docs}
+}
@@ -98,6 +98,23 @@ func (s *MapStorageValue) Take(key codec.Encodeable) StoredValue {
 	return s.DefaultValueFactory()
 }
 
+/// Does the value (explicitly) exist in storage?
+func (s *MapStorageValue) Exists(key codec.Encodeable) bool {
+	hasValue, _ := Get(s.keyFor(key))
+	return hasValue
+}
+
+/// Remove the value under a key.
+func (s *MapStorageValue) Remove(key codec.Encodeable) {
+	Kill(s.keyFor(key))
+}
+
+/// Move the value under a key to another key, without decoding it.
+/// Returns false if there is no value under the old key.
+func (s *MapStorageValue) Rekey(oldKey codec.Encodeable, newKey codec.Encodeable) bool {
+	return MoveValue(s.keyFor(oldKey), s.keyFor(newKey))
+}
+
 func (s *MapStorageValue) Insert(key codec.Encodeable, val codec.Encodeable) {
 	if val != nil {
 		Put(s.keyFor(key), codec.ToBytes(val))
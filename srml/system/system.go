@@ -2,11 +2,15 @@ package system
 
 import (
 	"bytes"
+	"errors"
+	"math"
 
 	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/srio"
 	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srversion"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
 	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
 	"github.com/Joystream/tinygo-wasm-substrate/srml/support/storage"
 	codec "github.com/kyegupov/parity-codec-go/noreflect"
@@ -25,6 +29,17 @@ type Module struct {
 	ParentHashStore     storage.SimpleStorageValue
 	ExtrinsicsRootStore storage.SimpleStorageValue
 	DigestStore         storage.SimpleStorageValue
+	// Total weight and length of all extrinsics applied so far in the current block
+	AllExtrinsicsWeightStore storage.SimpleStorageValue
+	AllExtrinsicsLenStore    storage.SimpleStorageValue
+
+	/// The version of the running runtime, i.e. what its Core_version returns.
+	Version srversion.RuntimeVersion
+	/// The runtime version which executed the last block.
+	LastRuntimeVersionStore storage.SimpleStorageValue
+	/// The runtime version which executed the previous block, if the runtime
+	/// was upgraded since then. Only present during the execution of a block.
+	UpgradedFromStore storage.SimpleStorageValue
 }
 
 func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
@@ -63,7 +78,7 @@ func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
 	m.EventsStore = storage.SimpleStorageValue{
 		[]byte("System Events"),
 		"",
-		func() storage.StoredValue { return []EventRecord{} },
+		func() storage.StoredValue { return EventRecords{} },
 		m.DecodeEventRecords,
 	}
 	m.ExtrinsicDataStore = storage.MapStorageValue{
@@ -117,7 +132,7 @@ func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
 		},
 	}
 	m.DigestStore = storage.SimpleStorageValue{
-		[]byte("System ExtrinsicsRoot"),
+		[]byte("System Digest"),
 		"",
 		func() storage.StoredValue { return srprimitives.Digest{} },
 		func(pd codec.Decoder) storage.StoredValue {
@@ -129,6 +144,40 @@ func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
 			return d
 		},
 	}
+	m.AllExtrinsicsWeightStore = storage.SimpleStorageValue{
+		[]byte("System AllExtrinsicsWeight"),
+		"",
+		func() storage.StoredValue { return gohelpers.Uint32(0) },
+		func(pd codec.Decoder) storage.StoredValue {
+			return gohelpers.Uint32(pd.DecodeUint32())
+		},
+	}
+	m.AllExtrinsicsLenStore = storage.SimpleStorageValue{
+		[]byte("System AllExtrinsicsLen"),
+		"",
+		func() storage.StoredValue { return gohelpers.Uint32(0) },
+		func(pd codec.Decoder) storage.StoredValue {
+			return gohelpers.Uint32(pd.DecodeUint32())
+		},
+	}
+	m.LastRuntimeVersionStore = storage.SimpleStorageValue{
+		[]byte("System LastRuntimeVersion"),
+		"",
+		func() storage.StoredValue { return nil },
+		decodeRuntimeVersion,
+	}
+	m.UpgradedFromStore = storage.SimpleStorageValue{
+		[]byte("System UpgradedFrom"),
+		"",
+		func() storage.StoredValue { return nil },
+		decodeRuntimeVersion,
+	}
+}
+
+func decodeRuntimeVersion(pd codec.Decoder) storage.StoredValue {
+	var v srversion.RuntimeVersion
+	v.ParityDecode(pd)
+	return &v
 }
 
 func (m *Module) Initialise(number srprimitives.BlockNumber, parentHash srprimitives.Hash, txsRoot srprimitives.Hash) {
@@ -139,6 +188,36 @@ func (m *Module) Initialise(number srprimitives.BlockNumber, parentHash srprimit
 	m.ExtrinsicsRootStore.Put(txsRoot)
 	m.RandomSeedStore.Put(m.CalculateRandom())
 	m.EventsStore.Kill()
+	m.noteRuntimeVersion()
+}
+
+/// Records the version of the running runtime, keeping the previous one for
+/// RuntimeUpgraded if the runtime was upgraded since the last block.
+/// The chains which did not record the version yet are not considered upgraded.
+/// The version is only written when it is absent or upgraded, not on every block.
+func (m *Module) noteRuntimeVersion() {
+	last := m.LastRuntimeVersionStore.Get()
+	if last == nil {
+		m.LastRuntimeVersionStore.Put(&m.Version)
+	} else if isUpgrade(last.(*srversion.RuntimeVersion), &m.Version) {
+		m.UpgradedFromStore.Put(last.(*srversion.RuntimeVersion))
+		m.LastRuntimeVersionStore.Put(&m.Version)
+	}
+}
+
+/// A runtime upgrade changes the specification; changes of the implementation only do not count.
+func isUpgrade(from *srversion.RuntimeVersion, to *srversion.RuntimeVersion) bool {
+	return from.SpecName != to.SpecName || from.SpecVersion != to.SpecVersion
+}
+
+/// Whether the runtime was upgraded since the previous block, and the version which executed it.
+/// Meant for the modules to run their storage migrations in OnInitialise.
+func (m *Module) RuntimeUpgraded() (bool, *srversion.RuntimeVersion) {
+	from := m.UpgradedFromStore.Get()
+	if from == nil {
+		return false, nil
+	}
+	return true, from.(*srversion.RuntimeVersion)
 }
 
 /// Remove temporary "environment" entries in storage.
@@ -146,18 +225,22 @@ func (m *Module) Finalise() srprimitives.Header {
 
 	m.RandomSeedStore.Kill()
 	m.ExtrinsicCountStore.Kill()
+	m.AllExtrinsicsWeightStore.Kill()
+	m.AllExtrinsicsLenStore.Kill()
+	m.UpgradedFromStore.Kill()
 
 	number := m.NumberStore.Take().(srprimitives.BlockNumber)
 	parentHash := m.ParentHashStore.Take().(srprimitives.HashOutput)
 	digest := m.DigestStore.Take().(srprimitives.Digest)
 	extrinsicsRoot := m.ExtrinsicsRootStore.Take().(srprimitives.HashOutput)
-	storageRoot := srio.StorageRoot()
-	has, storageChangesRoot := srio.StorageChangesRoot(parentHash.AsBytes(), number.MinusOne().AsUint64())
+	hasher := m.TypeParamsFactory.Hasher()
+	storageRoot := hasher.StorageRoot()
+	has, storageChangesRoot := hasher.StorageChangesRoot(parentHash, number.MinusOne().AsUint64())
 
 	// we can't compute changes trie root earlier && put it to the Digest
 	// because it will include all currently existing temporaries
 	if has {
-		item := srprimitives.ChangesTrieRoot(*storageChangesRoot)
+		item := srprimitives.ChangesTrieRoot(*storageChangesRoot.(*primitives.H256))
 		digest.Logs = append(digest.Logs, item)
 	}
 
@@ -166,10 +249,35 @@ func (m *Module) Finalise() srprimitives.Header {
 	return srprimitives.Header{parentHash, number, storageRoot, extrinsicsRoot, digest}
 }
 
+/// Writes the initial state of the module into the storage.
+func (m *Module) BuildStorage() {
+	m.BlockHashStore.Insert(m.TypeParamsFactory.BlockNumber(0), m.TypeParamsFactory.NewHash(69))
+	m.LastRuntimeVersionStore.Put(&m.Version)
+	srio.UnhashedPut(srio.EXTRINSIC_INDEX, codec.ToBytesCustom(func(pe codec.Encoder) { pe.EncodeUint32(0) }))
+}
+
+/// Deposits a log and ensures it matches the block's log data.
+func (m *Module) DepositLog(item srprimitives.DigestItem) {
+	digest := m.DigestStore.Get().(srprimitives.Digest)
+	digest.Logs = append(digest.Logs, item)
+	m.DigestStore.Put(digest)
+}
+
 /// Calculate the current block's random seed.
 func (m *Module) CalculateRandom() srprimitives.HashOutput {
-	blockNumber := m.NumberStore.Get().(srprimitives.BlockNumber)
-	zeroBlockNumber := m.TypeParamsFactory.BlockNumber(0)
+	return calculateRandom(
+		m.NumberStore.Get().(srprimitives.BlockNumber),
+		func(n srprimitives.BlockNumber) srprimitives.HashOutput {
+			return m.BlockHashStore.Get(n).(srprimitives.HashOutput)
+		},
+		m.TypeParamsFactory.NewHash(0),
+	)
+}
+
+// Mixes the hashes of the 81 blocks before the parent of the block, the block 0 repeating
+// when there are not enough, into result (a zero hash)
+func calculateRandom(blockNumber srprimitives.BlockNumber, blockHash func(srprimitives.BlockNumber) srprimitives.HashOutput, result srprimitives.HashOutput) srprimitives.HashOutput {
+	zeroBlockNumber := blockNumber.Zero()
 	gohelpers.Assert(blockNumber.GreaterThan(zeroBlockNumber), "Block number may never be zero")
 	state := blockNumber.MinusOne()
 	hashes := []srprimitives.HashOutput{}
@@ -177,10 +285,10 @@ func (m *Module) CalculateRandom() srprimitives.HashOutput {
 		if state.GreaterThan(zeroBlockNumber) {
 			state = state.MinusOne()
 		}
-		hashes = append(hashes, m.BlockHashStore.Get(state).(srprimitives.HashOutput))
+		hashes = append(hashes, blockHash(state))
 	}
 
-	return srprimitives.TripletMix(hashes)
+	return srprimitives.TripletMix(hashes, result)
 }
 
 func (m *Module) ExtrinsicIndex() (bool, uint32) {
@@ -204,16 +312,37 @@ func (m *Module) NoteExtrinsic(encodedXt []byte) {
 	m.ExtrinsicDataStore.Insert(gohelpers.Uint32(index), gohelpers.ByteSlice(encodedXt))
 }
 
+/// Gets the total weight of all extrinsics applied so far in the current block.
+func (m *Module) AllExtrinsicsWeight() srprimitives.Weight {
+	return srprimitives.Weight(m.AllExtrinsicsWeightStore.Get().(gohelpers.Uint32))
+}
+
+/// Gets the total encoded length of all extrinsics applied so far in the current block.
+func (m *Module) AllExtrinsicsLen() uint32 {
+	return uint32(m.AllExtrinsicsLenStore.Get().(gohelpers.Uint32))
+}
+
 /// To be called immediately after an extrinsic has been applied.
-func (m *Module) NoteAppliedExtrinsic(maybeError error) {
-	if maybeError != nil {
-		m.DepositEventCall(EventExtrinsicSuccess{}).Dispatch(nil)
+func (m *Module) NoteAppliedExtrinsic(maybeError error, weight srprimitives.Weight, encodedLen uint32) {
+	if maybeError == nil {
+		m.DepositEvent(m.OuterEvent(EventExtrinsicSuccess{}))
 	} else {
-		m.DepositEventCall(EventExtrinsicFailed{}).Dispatch(nil)
+		m.DepositEvent(m.OuterEvent(EventExtrinsicFailed{}))
 	}
 	_, exInd := m.ExtrinsicIndex()
 	nextExtrinsicIndex := exInd + 1
+	totalWeight := saturatingAddUint32(uint32(m.AllExtrinsicsWeight()), uint32(weight))
+	totalLength := saturatingAddUint32(m.AllExtrinsicsLen(), encodedLen)
 	srio.UnhashedPut(srio.EXTRINSIC_INDEX, codec.ToBytesCustom(func(pe codec.Encoder) { pe.EncodeUint32(nextExtrinsicIndex) }))
+	m.AllExtrinsicsWeightStore.Put(gohelpers.Uint32(totalWeight))
+	m.AllExtrinsicsLenStore.Put(gohelpers.Uint32(totalLength))
+}
+
+func saturatingAddUint32(a uint32, b uint32) uint32 {
+	if a+b < a {
+		return math.MaxUint32
+	}
+	return a + b
 }
 
 /// To be called immediately after `note_applied_extrinsic` of the last extrinsic of the block
@@ -231,13 +360,12 @@ func (m *Module) DeriveExtrinsics() {
 	if extrinsicCountStored != nil {
 		extrinsicCount = extrinsicCountStored.(uint32)
 	}
-	extrinsics := make([]codec.Encodeable, extrinsicCount)
+	extrinsics := make([][]byte, extrinsicCount)
 	for i := range extrinsics {
-		extrinsics[i] = m.ExtrinsicDataStore.Get(gohelpers.Uint32(i)).(srprimitives.Extrinsic).EncodeableEnum()
+		extrinsics[i] = m.ExtrinsicDataStore.Get(gohelpers.Uint32(i)).([]byte)
 	}
 
-	xtsRoot := primitives.H256(srio.EnumeratedTrieRootBlake256(extrinsics))
-	m.ExtrinsicsRootStore.Put(&xtsRoot)
+	m.ExtrinsicsRootStore.Put(m.TypeParamsFactory.Hasher().EnumeratedTrieRoot(extrinsics))
 }
 
 // Method IDs
@@ -258,6 +386,11 @@ func (m *Module) DepositEventCall(event support.Event) DepositEventCall {
 	return DepositEventCall{m, event}
 }
 
+/// Deposits an event into this block's event record, see support.BaseModule.OuterEvent
+func (m *Module) DepositEvent(event support.Event) {
+	m.DepositEventCall(event).Dispatch(nil)
+}
+
 func (c DepositEventCall) Dispatch(o srprimitives.Origin) error {
 
 	ok, extrinsicIndex := c.m.ExtrinsicIndex()
@@ -265,12 +398,18 @@ func (c DepositEventCall) Dispatch(o srprimitives.Origin) error {
 	if ok {
 		phase = PhaseApplyExtrinsic(extrinsicIndex)
 	}
-	events := c.m.EventsStore.Get().([]EventRecord)
+	events := c.m.EventsStore.Get().(EventRecords)
 	events = append(events, EventRecord{phase, c.event})
-	c.m.EventsStore.Put(EventRecords(events))
+	c.m.EventsStore.Put(events)
 	return nil
 }
 
+// The system module has no dispatchable calls: events are only deposited by the runtime itself
+func (m *Module) DecodeCall(pd codec.Decoder) srprimitives.Callable {
+	b := pd.DecodeByte()
+	panic(primitives.InvalidEnum(b, "system Call"))
+}
+
 func (m *Module) CallableBelongsToThisModule(c srprimitives.Callable) bool {
 	switch c.(type) {
 	case DepositEventCall:
@@ -279,7 +418,24 @@ func (m *Module) CallableBelongsToThisModule(c srprimitives.Callable) bool {
 	return false
 }
 
+/// A type used as the context for checking extrinsics: provides the current block number
+/// and the hashes of the previous blocks, as kept by the system module.
 type ChainContext struct {
+	System *Module
+	// Resolves the addresses of the signers, e.g. indices.Module
+	AddressLookup srprimitives.Lookup
+}
+
+func (c ChainContext) Lookup(a srprimitives.Address) (bool, srprimitives.AccountId) {
+	return c.AddressLookup.Lookup(a)
+}
+
+func (c ChainContext) CurrentHeight() srprimitives.BlockNumber {
+	return c.System.NumberStore.Get().(srprimitives.BlockNumber)
+}
+
+func (c ChainContext) BlockNumberToHash(n uint64) (bool, srprimitives.HashOutput) {
+	return true, c.System.BlockHashStore.Get(c.System.TypeParamsFactory.BlockNumber(n)).(srprimitives.HashOutput)
 }
 
 /// Origin for the system module.
@@ -305,6 +461,46 @@ type RawOriginInherent struct{}
 
 func (_ RawOriginInherent) ImplementsRawOrigin() {}
 
+/// An account is being created.
+type OnNewAccount interface {
+	/// A new account `who` has been registered.
+	OnNewAccount(who srprimitives.AccountId)
+}
+
+/// Determinator for whether a given account is able to be used.
+type IsDeadAccount interface {
+	/// Is the given account dead?
+	IsDeadAccount(who srprimitives.AccountId) bool
+}
+
+/// Ensure that the origin represents a signed extrinsic (i.e. transaction).
+/// Returns the signer's account id.
+func EnsureSigned(o srprimitives.Origin) (srprimitives.AccountId, error) {
+	signed, ok := o.(RawOriginAccountId)
+	if !ok {
+		return nil, errors.New("bad origin: expected to be a signed origin")
+	}
+	return signed.v, nil
+}
+
+/// Ensure that the origin represents the root.
+func EnsureRoot(o srprimitives.Origin) error {
+	_, ok := o.(RawOriginRoot)
+	if !ok {
+		return errors.New("bad origin: expected to be a root origin")
+	}
+	return nil
+}
+
+/// Ensure that the origin represents an unsigned extrinsic (i.e. inherent).
+func EnsureInherent(o srprimitives.Origin) error {
+	_, ok := o.(RawOriginInherent)
+	if !ok {
+		return errors.New("bad origin: expected to be an inherent origin")
+	}
+	return nil
+}
+
 func OptionAccountIdToOrigin(present bool, accountId srprimitives.AccountId) RawOrigin {
 	if present {
 		return RawOriginAccountId{accountId}
@@ -368,12 +564,12 @@ func (m *Module) DecodeEventRecord(pd codec.Decoder) EventRecord {
 }
 
 func (m *Module) DecodeEventRecords(pd codec.Decoder) storage.StoredValue {
-	var e *[]EventRecord
+	var e EventRecords
 	pd.DecodeCollection(
-		func(n int) { *e = make([]EventRecord, n) },
-		func(i int) { (*e)[i] = m.DecodeEventRecord(pd) },
+		func(n int) { e = make(EventRecords, n) },
+		func(i int) { e[i] = m.DecodeEventRecord(pd) },
 	)
-	return *e
+	return e
 }
 
 func (e EventRecords) ParityEncode(pe codec.Encoder) {
@@ -394,3 +590,40 @@ type EventExtrinsicFailed struct{}
 func (e EventExtrinsicFailed) ParityEncode(pe codec.Encoder) {
 	pe.EncodeByte(1)
 }
+
+func (m *Module) DecodeEvent(pd codec.Decoder) support.RawEvent {
+	b := pd.DecodeByte()
+	switch b {
+	case 0:
+		return EventExtrinsicSuccess{}
+	case 1:
+		return EventExtrinsicFailed{}
+	}
+	panic(primitives.InvalidEnum(b, "system Event"))
+}
+
+func (m *Module) StorageMetadata() metadata.StorageMetadata {
+	return metadata.StorageMetadata{"System", []metadata.StorageFunctionMetadata{
+		m.AccountNonceStore.Metadata("T::AccountId", "T::Index", "Extrinsics nonce for accounts."),
+		m.ExtrinsicCountStore.Metadata("u32", "Total extrinsics count for the current block."),
+		m.AllExtrinsicsWeightStore.Metadata("u32", "Total weight of all extrinsics for the current block."),
+		m.AllExtrinsicsLenStore.Metadata("u32", "Total length in bytes of all extrinsics for the current block."),
+		m.BlockHashStore.Metadata("T::BlockNumber", "T::Hash", "Map of block numbers to block hashes."),
+		m.ExtrinsicDataStore.Metadata("u32", "Vec<u8>", "Extrinsics data for the current block (maps extrinsic's index to its data)."),
+		m.RandomSeedStore.Metadata("T::Hash", "Random seed of the current block."),
+		m.NumberStore.Metadata("T::BlockNumber", "The current block number being processed. Set by `execute_block`."),
+		m.ParentHashStore.Metadata("T::Hash", "Hash of the previous block."),
+		m.ExtrinsicsRootStore.Metadata("T::Hash", "Extrinsics root of the current block, also part of the block header."),
+		m.DigestStore.Metadata("T::Digest", "Digest of the current block, also part of the block header."),
+		m.EventsStore.Metadata("Vec<EventRecord<T::Event>>", "Events deposited for the current block."),
+		m.LastRuntimeVersionStore.Metadata("RuntimeVersion", "The runtime version which executed the last block."),
+		m.UpgradedFromStore.Metadata("RuntimeVersion", "The runtime version which executed the previous block, if the runtime was upgraded since."),
+	}}
+}
+
+func (m *Module) EventMetadata() []metadata.EventMetadata {
+	return []metadata.EventMetadata{
+		{"ExtrinsicSuccess", []string{}, []string{"An extrinsic completed successfully."}},
+		{"ExtrinsicFailed", []string{}, []string{"An extrinsic failed."}},
+	}
+}
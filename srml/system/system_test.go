@@ -0,0 +1,55 @@
+package system
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	"golang.org/x/crypto/blake2b"
+)
+
+// The host storage is not available natively, the block hashes are stored in a slice.
+// The expected seeds were computed with the code of Substrate 1.0's calculate_random
+// and safe-mix 1.0.0's TripletMix, run in Rust on the same block hashes.
+
+// The hashes of the blocks 0..n-1, as Blake2-256 of the block number byte
+func storedBlockHashes(n int) func(srprimitives.BlockNumber) srprimitives.HashOutput {
+	hashes := make([]primitives.H256, n)
+	for i := range hashes {
+		hashes[i] = primitives.H256(blake2b.Sum256([]byte{byte(i)}))
+	}
+	return func(number srprimitives.BlockNumber) srprimitives.HashOutput {
+		h := hashes[number.AsUint64()]
+		return &h
+	}
+}
+
+func TestCalculateRandom(t *testing.T) {
+	cases := []struct {
+		number   uint64
+		expected string
+	}{
+		// The hashes of the blocks 98 down to 18
+		{100, "0fca1053bb412df4823a595fb28ec80d51a61b0f5d1b7ab6ecaea98ba731dad8"},
+		// The hash of the block 0 is repeated, and is the majority
+		{30, "03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314"},
+		{1, "03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314"},
+	}
+	for _, c := range cases {
+		seed := calculateRandom(gohelpers.NewUint64(c.number), storedBlockHashes(100), &primitives.H256{})
+		if hex.EncodeToString(seed.AsBytes()) != c.expected {
+			t.Errorf("block %d: got %x, expected %s", c.number, seed.AsBytes(), c.expected)
+		}
+	}
+}
+
+func TestCalculateRandomAtZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("the block number 0 should panic")
+		}
+	}()
+	calculateRandom(gohelpers.NewUint64(0), storedBlockHashes(1), &primitives.H256{})
+}
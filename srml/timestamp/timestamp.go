@@ -0,0 +1,288 @@
+package timestamp
+
+import (
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/inherents"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/primitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srcore/srprimitives"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/metadata"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/support/storage"
+	"github.com/Joystream/tinygo-wasm-substrate/srml/system"
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// The timestamp module allows the validators to set and validate a timestamp with each block.
+//
+// It uses inherents for timestamp data, which is provided by the block author and validated/verified
+// by other validators. The timestamp can be set only once per block and must be set each block.
+// There could be a constraint on how much time must pass before setting the new timestamp.
+
+/// The identifier for the `timestamp` inherent.
+var InherentIdentifier = inherents.InherentIdentifier{'t', 'i', 'm', 's', 't', 'a', 'p', '0'}
+
+/// The maximum drift (in seconds) of the timestamp of a block from the local time of the validator.
+const MaxTimestampDrift = 60
+
+/// The type for recording the timestamp: seconds since the Unix epoch, as in the node template.
+type Moment uint64
+
+func (m Moment) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint64(uint64(m))
+}
+
+/// A trait which is called when the timestamp is set.
+type OnTimestampSet interface {
+	OnTimestampSet(moment Moment)
+}
+
+/// Errors that can occur while checking the timestamp inherent.
+type InherentError interface {
+	inherents.CheckInherentError
+	ImplementsInherentError()
+}
+
+/// The timestamp is valid in the future.
+/// This is a non-fatal-error and will not stop checking the inherents.
+type InherentErrorValidAtTimestamp Moment
+
+func (_ InherentErrorValidAtTimestamp) ImplementsInherentError() {}
+
+func (_ InherentErrorValidAtTimestamp) IsFatalError() bool { return false }
+
+func (e InherentErrorValidAtTimestamp) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(0)
+	pe.EncodeUint64(uint64(e))
+}
+
+/// Some other error.
+type InherentErrorOther string
+
+func (_ InherentErrorOther) ImplementsInherentError() {}
+
+func (_ InherentErrorOther) IsFatalError() bool { return true }
+
+func (e InherentErrorOther) ParityEncode(pe codec.Encoder) {
+	pe.EncodeByte(1)
+	pe.EncodeString(string(e))
+}
+
+/// Get the timestamp inherent data, or false if it is missing.
+func ExtractInherentData(data *inherents.InherentData) (bool, Moment) {
+	ok, t := data.GetData(InherentIdentifier, func(pd codec.Decoder) interface{} {
+		return Moment(pd.DecodeUint64())
+	})
+	if !ok {
+		return false, 0
+	}
+	return true, t.(Moment)
+}
+
+type Module struct {
+	support.BaseModule
+	TypeParamsFactory support.TypeParamsFactory
+	/// Something which can be notified when the timestamp is set. Can be nil.
+	OnTimestampSet OnTimestampSet
+
+	/// Current time for the current block.
+	NowStore storage.SimpleStorageValue
+	/// The minimum period between blocks. Beware that this is different to the *expected* period
+	/// that the block production apparatus provides. Your chosen consensus system will generally
+	/// work with this to determine a sensible block time. e.g. For Aura, it will be double this
+	/// period on default settings.
+	MinimumPeriodStore storage.SimpleStorageValue
+	/// Did the timestamp get updated in this block?
+	DidUpdateStore storage.SimpleStorageValue
+}
+
+func (m *Module) InitForRuntime(r support.TypeParamsFactory) {
+	m.TypeParamsFactory = r
+
+	m.NowStore = storage.SimpleStorageValue{
+		[]byte("Timestamp Now"),
+		"",
+		func() storage.StoredValue { return Moment(0) },
+		func(pd codec.Decoder) storage.StoredValue { return Moment(pd.DecodeUint64()) },
+	}
+	m.MinimumPeriodStore = storage.SimpleStorageValue{
+		[]byte("Timestamp MinimumPeriod"),
+		"",
+		func() storage.StoredValue { return Moment(3) },
+		func(pd codec.Decoder) storage.StoredValue { return Moment(pd.DecodeUint64()) },
+	}
+	m.DidUpdateStore = storage.SimpleStorageValue{
+		[]byte("Timestamp DidUpdate"),
+		"",
+		func() storage.StoredValue { return didUpdate(false) },
+		func(pd codec.Decoder) storage.StoredValue { return didUpdate(pd.DecodeBool()) },
+	}
+}
+
+type didUpdate bool
+
+func (d didUpdate) ParityEncode(pe codec.Encoder) {
+	pe.EncodeBool(bool(d))
+}
+
+/// Initial state of the module
+type GenesisConfig struct {
+	MinimumPeriod Moment
+}
+
+/// Writes the initial state of the module into the storage.
+func (m *Module) BuildStorage(config GenesisConfig) {
+	m.MinimumPeriodStore.Put(config.MinimumPeriod)
+}
+
+/// Get the current time for the current block.
+///
+/// NOTE: if this function is called prior to setting the timestamp,
+/// it will return the timestamp of the previous block.
+func (m *Module) Now() Moment {
+	return m.NowStore.Get().(Moment)
+}
+
+func (m *Module) MinimumPeriod() Moment {
+	return m.MinimumPeriodStore.Get().(Moment)
+}
+
+/// Set the timestamp to something in particular. Only used for tests.
+func (m *Module) SetTimestamp(now Moment) {
+	m.NowStore.Put(now)
+}
+
+func (m *Module) OnFinalise(n srprimitives.BlockNumber) {
+	if !m.DidUpdateStore.Take().(didUpdate) {
+		panic("Timestamp must be updated once in the block")
+	}
+}
+
+// Method IDs
+const (
+	SetId byte = 0
+)
+
+/// Set the current time.
+///
+/// This call should be invoked exactly once per block. It will panic at the finalization phase,
+/// if this call hasn't been invoked by that time.
+///
+/// The timestamp should be greater than the previous one by the amount specified by `MinimumPeriod`.
+///
+/// The dispatch origin for this call must be `Inherent`.
+type SetCall struct {
+	m   *Module
+	Now Moment
+}
+
+func (m *Module) SetCall(now Moment) SetCall {
+	return SetCall{m, now}
+}
+
+func (c SetCall) EncodeableEnum() primitives.EncodeableEnum {
+	return primitives.EncodeableEnum{SetId, c}
+}
+
+// Encodes the arguments of the call
+func (c SetCall) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUintCompact(uint64(c.Now))
+}
+
+func (c SetCall) Dispatch(o srprimitives.Origin) error {
+	if err := system.EnsureInherent(o); err != nil {
+		return err
+	}
+	m := c.m
+	if m.DidUpdateStore.Get().(didUpdate) {
+		panic("Timestamp must be updated only once in the block")
+	}
+	prev := m.Now()
+	if prev != 0 && c.Now < prev+m.MinimumPeriod() {
+		panic("Timestamp must increment by at least <MinimumPeriod> between sequential blocks")
+	}
+	m.NowStore.Put(c.Now)
+	m.DidUpdateStore.Put(didUpdate(true))
+
+	if m.OnTimestampSet != nil {
+		m.OnTimestampSet.OnTimestampSet(c.Now)
+	}
+	return nil
+}
+
+func (m *Module) DecodeCall(pd codec.Decoder) srprimitives.Callable {
+	b := pd.DecodeByte()
+	switch b {
+	case SetId:
+		return SetCall{m, Moment(pd.DecodeUintCompact())}
+	}
+	panic(primitives.InvalidEnum(b, "timestamp Call"))
+}
+
+func (m *Module) CallableBelongsToThisModule(c srprimitives.Callable) bool {
+	switch c.(type) {
+	case SetCall:
+		return true
+	}
+	return false
+}
+
+// Implements inherents.ProvideInherent
+
+func (m *Module) InherentIdentifier() inherents.InherentIdentifier {
+	return InherentIdentifier
+}
+
+func (m *Module) CreateInherent(data *inherents.InherentData) srprimitives.Callable {
+	ok, t := ExtractInherentData(data)
+	if !ok {
+		panic("Timestamp inherent data must be provided")
+	}
+	nextTime := m.Now() + m.MinimumPeriod()
+	if t > nextTime {
+		nextTime = t
+	}
+	return SetCall{m, nextTime}
+}
+
+func (m *Module) CheckInherent(call srprimitives.Callable, data *inherents.InherentData) inherents.CheckInherentError {
+	set, ok := call.(SetCall)
+	if !ok {
+		return nil
+	}
+	t := set.Now
+
+	ok, dataT := ExtractInherentData(data)
+	if !ok {
+		return InherentErrorOther("Timestamp inherent data is not provided.")
+	}
+
+	minimum := m.Now() + m.MinimumPeriod()
+	if t > dataT+MaxTimestampDrift {
+		return InherentErrorOther("Timestamp too far in future to accept")
+	} else if t < minimum {
+		return InherentErrorValidAtTimestamp(minimum)
+	}
+	return nil
+}
+
+func (m *Module) CallMetadata() []metadata.FunctionMetadata {
+	return []metadata.FunctionMetadata{
+		{uint16(SetId), "set", []metadata.FunctionArgumentMetadata{{"now", "Compact<T::Moment>"}}, []string{
+			"Set the current time.",
+			"",
+			"This call should be invoked exactly once per block. It will panic at the finalization phase,",
+			"if this call hasn't been invoked by that time.",
+			"",
+			"The timestamp should be greater than the previous one by the amount specified by `MinimumPeriod`.",
+			"",
+			"The dispatch origin for this call must be `Inherent`.",
+		}},
+	}
+}
+
+func (m *Module) StorageMetadata() metadata.StorageMetadata {
+	return metadata.StorageMetadata{"Timestamp", []metadata.StorageFunctionMetadata{
+		m.NowStore.Metadata("T::Moment", "Current time for the current block."),
+		m.MinimumPeriodStore.Metadata("T::Moment", "The minimum period between blocks."),
+		m.DidUpdateStore.Metadata("bool", "Did the timestamp get updated in this block?"),
+	}}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"strconv"
 
 	"github.com/Joystream/tinygo-wasm-substrate/gohelpers"
@@ -29,16 +30,17 @@ import (
 // (export "AuraApi_slot_duration" (func $AuraApi_slot_duration))
 
 //go:export Core_version
-func coreVersion(_ *byte, _ uint32) uint64 {
-	return ReturnSlice(paritycodec.ToBytes(
-		srversion.RuntimeVersion{
+func coreVersion(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd paritycodec.Decoder) paritycodec.Encodeable {
+		return srversion.RuntimeVersion{
 			"test",
 			"parity-test",
 			1,
 			1,
 			1,
-			[]srversion.ApiVersion{},
-		}))
+			srversion.ImplementedApis(srversion.CoreApi),
+		}
+	})
 }
 
 type AccountId primitives.H256
@@ -77,7 +79,7 @@ type Extrinsic interface {
 }
 
 func EncodeExtrinsic(ex Extrinsic, pe paritycodec.Encoder) {
-	ex.EncodeableEnum().ParityEncode(pe)
+	ex.ParityEncode(pe)
 }
 
 type typeParamsFactory struct{}
@@ -98,8 +100,7 @@ func (_ typeParamsFactory) DecodeExtrinsic(pd paritycodec.Decoder) srprimitives.
 
 func (_ typeParamsFactory) NewHashOutput() srprimitives.HashOutput { return &primitives.H256{} }
 func (_ typeParamsFactory) BlockNumber(i uint64) srprimitives.BlockNumber {
-	v := gohelpers.Uint64(i)
-	return &v
+	return gohelpers.NewUint64(i)
 }
 func (_ typeParamsFactory) DecodeDigestItem(pd paritycodec.Decoder) srprimitives.DigestItem {
 	return srprimitives.DecodeDigestItem(pd, authorityIdFactory)
@@ -126,14 +127,11 @@ func (e *TransferExtrinsic) ParityDecode(pd paritycodec.Decoder) {
 }
 
 func (e TransferExtrinsic) ParityEncode(pe paritycodec.Encoder) {
+	pe.EncodeByte(1)
 	e.transfer.ParityEncode(pe)
 	(*primitives.H512)(&e.signature).ParityEncode(pe)
 }
 
-func (e TransferExtrinsic) EncodeableEnum() primitives.EncodeableEnum {
-	return primitives.EncodeableEnum{1, e}
-}
-
 func authorityIdFactory() srprimitives.AuthorityId { return &primitives.Ed25519AuthorityId{} }
 
 type Result struct {
@@ -170,10 +168,67 @@ const (
 
 var NONCE_OF = []byte("nonce:")
 var BALANCE_OF = []byte("balance:")
+var NUMBER = []byte("TestRuntime Number")
+var PARENT_HASH = []byte("TestRuntime ParentHash")
+
+// Well known (unhashed) keys of the authority set, see consensus
+var AUTHORITY_AT = []byte(":auth:")
+var AUTHORITY_COUNT = []byte(":auth:len")
+
+type Authorities []primitives.Ed25519AuthorityId
+
+func (a Authorities) ParityEncode(pe paritycodec.Encoder) {
+	pe.EncodeCollection(len(a), func(i int) { a[i].ParityEncode(pe) })
+}
+
+func authorities() Authorities {
+	ok, count := srio.UnhashedGet(AUTHORITY_COUNT)
+	if !ok {
+		panic("There are always authorities in test-runtime")
+	}
+	a := make(Authorities, paritycodec.Decoder{bytes.NewBuffer(count)}.DecodeUint32())
+	for i := range a {
+		key := paritycodec.ToBytesCustom(func(pe paritycodec.Encoder) {
+			pe.Write(AUTHORITY_AT)
+			pe.EncodeUint32(uint32(i))
+		})
+		ok, value := srio.UnhashedGet(key)
+		if !ok {
+			panic("Authority at index " + strconv.Itoa(i) + " should exist")
+		}
+		a[i].ParityDecode(paritycodec.Decoder{bytes.NewBuffer(value)})
+	}
+	return a
+}
+
+//go:export Core_authorities
+func coreAuthorities(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, func(pd paritycodec.Decoder) paritycodec.Encodeable {
+		return authorities()
+	})
+}
+
+//go:export Core_initialise_block
+func initialiseBlockExport(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, initialiseBlock)
+}
+
+func initialiseBlock(pd paritycodec.Decoder) paritycodec.Encodeable {
+	header := srprimitives.Header{}
+	header.ParityDecode(pd, typeParamsFactory{})
+
+	// populate environment.
+	storage.Put(NUMBER, paritycodec.ToBytes(header.Number))
+	storage.Put(PARENT_HASH, paritycodec.ToBytes(header.ParentHash))
+	storage.Put(srio.EXTRINSIC_INDEX, paritycodec.ToBytesCustom(func(pe paritycodec.Encoder) { pe.EncodeUint32(0) }))
+	return nil
+}
 
 func executeTransactionBackend(utx TransferExtrinsic) Result {
 	// check signature
-	utx.signature.Verify(paritycodec.ToBytes(&utx.transfer), primitives.H256(utx.transfer.from))
+	if !utx.signature.Verify(paritycodec.ToBytes(&utx.transfer), &utx.transfer.from) {
+		return Err(BadSignature)
+	}
 
 	// check nonce
 	nonce_key := ConcatByteSlices(NONCE_OF, paritycodec.ToBytes(&utx.transfer.from))
@@ -213,10 +268,12 @@ func digestEqual(d1 srprimitives.Digest, d2 srprimitives.Digest) bool {
 }
 
 //go:export Core_execute_block
-func executeBlock(offset *byte, length uintptr) uint64 {
+func executeBlockExport(data *byte, length uintptr) uint64 {
+	return CallApi(data, length, executeBlock)
+}
+
+func executeBlock(pd paritycodec.Decoder) paritycodec.Encodeable {
 	block := srprimitives.Block{}
-	mr := NewMemReader(offset, length)
-	pd := paritycodec.Decoder{&mr}
 	block.ParityDecode(pd, typeParamsFactory{})
 
 	// check transaction trie root represents the transactions.
@@ -260,7 +317,7 @@ func executeBlock(offset *byte, length uintptr) uint64 {
 	if !digestEqual(digest, block.Header.Digest) {
 		panic("Header digest items must match that calculated.")
 	}
-	return 0
+	return nil
 }
 
 // TODO: learn to build WASM modules in TinyGo without main()
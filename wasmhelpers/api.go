@@ -0,0 +1,48 @@
+package wasmhelpers
+
+import (
+	codec "github.com/kyegupov/parity-codec-go/noreflect"
+)
+
+// Runtime API functions are exported with the signature
+//
+//     func(data *byte, length uintptr) uint64
+//
+// where the input is the SCALE-encoded tuple of the arguments, and the result is
+// the SCALE-encoded return value, packed as returned by ReturnSlice.
+// Since TinyGo needs a plain function for each export, the exports are one-liners:
+//
+//     //go:export Core_execute_block
+//     func execute_block(data *byte, length uintptr) uint64 {
+//         return CallApi(data, length, executeBlock)
+//     }
+
+// A typed runtime API function: decodes its arguments (using the runtime's type factories),
+// and returns the result to be encoded, or nil if it returns nothing.
+// The nil must be untyped: a nil pointer returned as an Encodeable is not nil, and would be
+// dereferenced when encoding (the codec does not use reflection to check it).
+type ApiHandler func(pd codec.Decoder) codec.Encodeable
+
+// Calls the handler with the input of an exported function, and returns the encoded result
+func CallApi(data *byte, length uintptr, handler ApiHandler) uint64 {
+	mr := NewMemReader(data, length)
+	result := handler(codec.Decoder{&mr})
+	if result == nil {
+		return 0
+	}
+	return ReturnSlice(codec.ToBytes(result))
+}
+
+// Encodes a result with a function, for the results which are not Encodeable themselves
+type EncodeFunc func(pe codec.Encoder)
+
+func (f EncodeFunc) ParityEncode(pe codec.Encoder) {
+	f(pe)
+}
+
+// A u64 result
+type Uint64 uint64
+
+func (v Uint64) ParityEncode(pe codec.Encoder) {
+	pe.EncodeUint64(uint64(v))
+}